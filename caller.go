@@ -0,0 +1,177 @@
+package golog
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/millken/golog/internal/stack"
+)
+
+// CallerFormat defines how encoders render the path of the caller.
+type CallerFormat string
+
+const (
+	// CallerFormatFull renders the absolute path of the file. This is the default.
+	CallerFormatFull CallerFormat = "full"
+	// CallerFormatShort renders the file and its directory, as in pkg/file.go.
+	CallerFormatShort CallerFormat = "short"
+	// CallerFormatRelative renders the path relative to the root of the main module,
+	// or the absolute path for files outside of it.
+	CallerFormatRelative CallerFormat = "relative"
+)
+
+// CallerFormatter renders the frame of the caller.
+type CallerFormatter func(frame runtime.Frame) string
+
+// Helper marks the calling function as a logging helper. When resolving the caller of
+// an entry, helpers are skipped, so that wrappers around golog, nested or not, report
+// the code calling them without adjusting CallerSkip.
+func Helper() {
+	stack.MarkHelper(1)
+}
+
+// callerString renders frame as file:line, followed by the function name in parentheses if function is set.
+func callerString(frame runtime.Frame, format CallerFormat, function bool, formatter CallerFormatter) string {
+	if formatter != nil {
+		return formatter(frame)
+	}
+	s := callerPath(frame, format) + ":" + strconv.Itoa(frame.Line)
+	if function && frame.Function != "" {
+		s += " (" + frame.Function + ")"
+	}
+	return s
+}
+
+// callerPath renders the file of frame as configured by format.
+func callerPath(frame runtime.Frame, format CallerFormat) string {
+	switch format {
+	case CallerFormatShort:
+		return shortPath(frame.File)
+	case CallerFormatRelative:
+		return relativePath(frame)
+	}
+	return frame.File
+}
+
+// shortPath returns the last directory and the name of file.
+func shortPath(file string) string {
+	i := strings.LastIndexByte(file, '/')
+	if i < 0 {
+		return file
+	}
+	if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+		return file[j+1:]
+	}
+	return file
+}
+
+// gologPackage is the import path of this package.
+var gologPackage = reflect.TypeOf((*Log)(nil)).Elem().PkgPath()
+
+// isInternalFrame reports whether frame belongs to the runtime or to golog, tests excepted.
+func isInternalFrame(frame runtime.Frame) bool {
+	fn := frame.Function
+	if strings.HasPrefix(fn, "runtime.") {
+		return true
+	}
+	if !strings.HasPrefix(fn, gologPackage) {
+		return false
+	}
+	rest := fn[len(gologPackage):]
+	return strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "/internal/")
+}
+
+var mainModule struct {
+	once sync.Once
+	// dir is the directory of the main module, found from the working directory.
+	dir string
+	// path is the import path of the main module, prefixing files in builds with -trimpath.
+	path string
+	// root is the directory the main module was built in, found from the frames of its packages.
+	root atomic.Pointer[string]
+}
+
+// relativePath returns the file of frame relative to the root of the main module.
+// The root is found by matching the package of the function of frame against the main
+// module, so that it is known in deployed binaries, away from the sources.
+func relativePath(frame runtime.Frame) string {
+	mainModule.once.Do(func() {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			mainModule.path = bi.Main.Path
+		}
+		mainModule.dir = findModuleDir()
+	})
+	file := frame.File
+	if root, ok := moduleRoot(frame); ok {
+		if known := mainModule.root.Load(); known == nil || *known != root {
+			mainModule.root.Store(&root)
+		}
+		return file[len(root)+1:]
+	}
+	if root := mainModule.root.Load(); root != nil && strings.HasPrefix(file, *root+"/") {
+		return file[len(*root)+1:]
+	}
+	if dir := mainModule.dir; dir != "" && strings.HasPrefix(file, dir+"/") {
+		return file[len(dir)+1:]
+	}
+	if p := mainModule.path; p != "" && strings.HasPrefix(file, p+"/") {
+		return file[len(p)+1:]
+	}
+	return file
+}
+
+// moduleRoot returns the root of the main module, from the file of a frame of one of its packages:
+// the directory of the file without the path of the package within the module.
+func moduleRoot(frame runtime.Frame) (string, bool) {
+	mod := mainModule.path
+	pkg := strings.TrimSuffix(funcPackage(frame.Function), "_test")
+	if mod == "" || (pkg != mod && !strings.HasPrefix(pkg, mod+"/")) {
+		return "", false
+	}
+	rel := pkg[len(mod):]
+	dir := path.Dir(frame.File)
+	if !strings.HasSuffix(dir, rel) {
+		return "", false
+	}
+	dir = dir[:len(dir)-len(rel)]
+	if dir == "" || dir == "." {
+		return "", false
+	}
+	return dir, true
+}
+
+// funcPackage returns the import path of the package of the function named fn.
+func funcPackage(fn string) string {
+	// Dots may only appear in the last element of the path, escaped as %2e.
+	i := strings.LastIndexByte(fn, '/')
+	if j := strings.IndexByte(fn[i+1:], '.'); j >= 0 {
+		fn = fn[:i+1+j]
+	}
+	return strings.ReplaceAll(fn, "%2e", ".")
+}
+
+// findModuleDir returns the first directory holding a go.mod file, from the working directory up.
+func findModuleDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.ToSlash(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
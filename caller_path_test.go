@@ -0,0 +1,34 @@
+package golog
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelativePath_Deployed(t *testing.T) {
+	require := require.New(t)
+	relativePath(runtime.Frame{})
+	known := mainModule.root.Load()
+	defer mainModule.root.Store(known)
+
+	require.Equal("httplog/httplog.go", relativePath(runtime.Frame{
+		Function: "github.com/millken/golog/httplog.(*Config).serve",
+		File:     "/srv/build/httplog/httplog.go",
+	}))
+	require.Equal("cmd/app/main.go", relativePath(runtime.Frame{Function: "main.main", File: "/srv/build/cmd/app/main.go"}))
+	require.Equal("log.go", relativePath(runtime.Frame{Function: "github.com/millken/golog.New", File: "github.com/millken/golog/log.go"}))
+	require.Equal("/usr/lib/go/src/net/http/server.go", relativePath(runtime.Frame{
+		Function: "net/http.HandlerFunc.ServeHTTP",
+		File:     "/usr/lib/go/src/net/http/server.go",
+	}))
+}
+
+func TestFuncPackage(t *testing.T) {
+	require := require.New(t)
+	require.Equal("github.com/millken/golog", funcPackage("github.com/millken/golog.(*Log).Info"))
+	require.Equal("github.com/millken/golog_test", funcPackage("github.com/millken/golog_test.TestLog.func1"))
+	require.Equal("gopkg.in/yaml.v3", funcPackage("gopkg.in/yaml%2ev3.Marshal"))
+	require.Equal("main", funcPackage("main.main"))
+}
@@ -0,0 +1,63 @@
+package golog_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerFormat_JSON(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  golog.JSONEncoderConfig
+		want string
+	}{
+		{"full", golog.JSONEncoderConfig{},
+			`"caller":"/.+/caller_test\.go:\d+"`},
+		{"short", golog.JSONEncoderConfig{CallerFormat: golog.CallerFormatShort},
+			`"caller":"[^/"]+/caller_test\.go:\d+"`},
+		{"relative", golog.JSONEncoderConfig{CallerFormat: golog.CallerFormatRelative},
+			`"caller":"caller_test\.go:\d+"`},
+		{"function", golog.JSONEncoderConfig{CallerFormat: golog.CallerFormatRelative, CallerFunction: true},
+			`"caller":"caller_test\.go:\d+ \(github\.com/millken/golog_test\.TestCallerFormat_JSON\.func1\)"`},
+		{"split", golog.JSONEncoderConfig{CallerFormat: golog.CallerFormatShort, CallerFunction: true, SplitCaller: true},
+			`"file":"[^/"]+/caller_test\.go","line":\d+,"function":"github\.com/millken/golog_test\.TestCallerFormat_JSON\.func1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf buffer.Buffer
+			tt.cfg.DisableTimestamp = true
+			log, err := golog.NewLoggerByConfig("caller", golog.Config{
+				Encoding:     golog.JSONEncoding,
+				JSONEncoder:  tt.cfg,
+				CallerLevels: []golog.Level{golog.INFO},
+				Handler:      golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+			})
+			require.NoError(t, err)
+			log.Info("called")
+			require.True(t, validJSON(buf.String()), buf.String())
+			require.Regexp(t, `^\{"level":"info","message":"called",`+tt.want+`\}`, buf.String())
+		})
+	}
+}
+
+func TestCallerFormat_Text(t *testing.T) {
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("caller", golog.Config{
+		TextEncoder: golog.TextEncoderConfig{
+			DisableTimestamp: true,
+			DisableColor:     true,
+			CallerFormatter: func(frame runtime.Frame) string {
+				return "@" + frame.Function
+			},
+		},
+		CallerLevels: []golog.Level{golog.INFO},
+		Handler:      golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(t, err)
+	log.Info("called")
+	require.Equal(t, "INFO @github.com/millken/golog_test.TestCallerFormat_Text called\n", buf.String())
+}
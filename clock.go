@@ -0,0 +1,35 @@
+package golog
+
+import "time"
+
+// Clock tells the time of entries and of file rotations.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to use a function as a Clock.
+type ClockFunc func() time.Time
+
+// Now calls f.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock reading the system time, used if no other is set.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// entryTime returns the time of e, or the current time for entries not built by a logger.
+func entryTime(e *Entry) time.Time {
+	if e.Time.IsZero() {
+		return time.Now()
+	}
+	return e.Time
+}
+
+// loggerClock returns the Clock of logger, or SystemClock for loggers other than Log.
+func loggerClock(logger Logger) Clock {
+	if l, ok := logger.(*Log); ok && l.clock != nil {
+		return l.clock
+	}
+	return SystemClock
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -47,6 +48,34 @@ type Config struct {
 	// StacktraceLevels is the default levels for show stacktrace.
 	StacktraceLevels []Level       `json:"stacktraceLevels" yaml:"stacktraceLevels"`
 	Handler          HandlerConfig `json:"handler" yaml:"handler"`
+	// Redact configures the redaction of sensitive values before encoding.
+	Redact RedactConfig `json:"redact" yaml:"redact"`
+	// DuplicateKeys is the policy for fields sharing a key: keep, last, first or rename.
+	DuplicateKeys DuplicateKeyPolicy `json:"duplicateKeys" yaml:"duplicateKeys"`
+	// TypeEncoders holds the type encoders of the logger, taking precedence over the ones
+	// registered with RegisterTypeEncoder.
+	TypeEncoders *TypeEncoders `json:"-" yaml:"-"`
+	// Diagnostics configures the diagnostics added to FATAL and PANIC entries.
+	Diagnostics DiagnosticsConfig `json:"diagnostics" yaml:"diagnostics"`
+	// OnFatal is the action after FATAL entries: exit (default), panic, goexit or noop.
+	OnFatal FatalAction `json:"onFatal" yaml:"onFatal"`
+	// OnPanic is the action after PANIC entries: exit, panic, goexit or noop.
+	// PANIC entries panic with their message if not set.
+	OnPanic FatalAction `json:"onPanic" yaml:"onPanic"`
+	// ExitCode is the exit code of the exit action, 1 if not set.
+	ExitCode int `json:"exitCode" yaml:"exitCode"`
+	// ExitFunc replaces os.Exit for the exit action.
+	ExitFunc func(code int) `json:"-" yaml:"-"`
+	// PanicFunc replaces panic for the panic action and the default action of PANIC entries.
+	PanicFunc func(v any) `json:"-" yaml:"-"`
+	// FatalHooks are called for FATAL and PANIC entries once written, before the action.
+	FatalHooks []FatalHook `json:"-" yaml:"-"`
+	// EntryHandlers receive the entries of the logger, in addition to its writer.
+	EntryHandlers []EntryHandler `json:"-" yaml:"-"`
+	// Clock tells the time of the entries, SystemClock if not set.
+	Clock Clock `json:"-" yaml:"-"`
+	// FingersCrossed configures the buffering of entries until one at a trigger level is logged.
+	FingersCrossed FingersCrossedConfig `json:"fingersCrossed" yaml:"fingersCrossed"`
 }
 
 // TextEncoderConfig is the configuration for the text encoder.
@@ -63,6 +92,18 @@ type TextEncoderConfig struct {
 	CallerSkipFrame int `json:"callerSkipFrame" yaml:"callerSkipFrame"`
 	// ShowModuleName shows the name of the logger.
 	ShowModuleName bool `json:"showModuleName" yaml:"showModuleName"`
+	// ErrorFormat is how error fields are rendered: message (default) or structured.
+	ErrorFormat ErrorFormat `json:"errorFormat" yaml:"errorFormat"`
+	// CallerFormat is how the caller path is rendered: full (default), short or relative.
+	CallerFormat CallerFormat `json:"callerFormat" yaml:"callerFormat"`
+	// CallerFunction adds the function name to the caller.
+	CallerFunction bool `json:"callerFunction" yaml:"callerFunction"`
+	// CallerFormatter renders the caller, replacing CallerFormat and CallerFunction.
+	CallerFormatter CallerFormatter `json:"-" yaml:"-"`
+	// StackDepth is the maximum number of frames of stack traces, 20 if not set.
+	StackDepth int `json:"stackDepth" yaml:"stackDepth"`
+	// FilterStack removes the frames of the runtime and of golog from stack traces.
+	FilterStack bool `json:"filterStack" yaml:"filterStack"`
 }
 
 // JSONEncoderConfig is the configuration for the JSONEncoder.
@@ -75,6 +116,23 @@ type JSONEncoderConfig struct {
 	CallerSkipFrame int `json:"callerSkipFrame" yaml:"callerSkipFrame"`
 	// ShowModuleName shows the name of the logger.
 	ShowModuleName bool `json:"showModuleName" yaml:"showModuleName"`
+	// ErrorFormat is how error fields are rendered: message (default) or structured.
+	ErrorFormat ErrorFormat `json:"errorFormat" yaml:"errorFormat"`
+	// CallerFormat is how the caller path is rendered: full (default), short or relative.
+	CallerFormat CallerFormat `json:"callerFormat" yaml:"callerFormat"`
+	// CallerFunction adds the function name to the caller.
+	CallerFunction bool `json:"callerFunction" yaml:"callerFunction"`
+	// CallerFormatter renders the caller, replacing CallerFormat and CallerFunction.
+	CallerFormatter CallerFormatter `json:"-" yaml:"-"`
+	// SplitCaller renders the caller as separate file, line and function keys.
+	// CallerFormatter is not used then.
+	SplitCaller bool `json:"splitCaller" yaml:"splitCaller"`
+	// StackDepth is the maximum number of frames of stack traces, 20 if not set.
+	StackDepth int `json:"stackDepth" yaml:"stackDepth"`
+	// FilterStack removes the frames of the runtime and of golog from stack traces.
+	FilterStack bool `json:"filterStack" yaml:"filterStack"`
+	// StructuredStack renders stack traces as an array of objects with function, file and line keys.
+	StructuredStack bool `json:"structuredStack" yaml:"structuredStack"`
 }
 
 // HandlerType defines the type of log handler.
@@ -100,6 +158,9 @@ type HandlerConfig struct {
 // FileConfig is a configuration for a file writer.
 type FileConfig struct {
 	Path string `json:"path" yaml:"path"`
+	// FlushInterval is the interval at which buffered data is flushed to the file.
+	// Data is only flushed when the buffer is full, on Sync and on Close if not set.
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval"`
 }
 
 func newConfigs() *Configs {
@@ -174,6 +235,35 @@ func SetStacktraceLevels(levels ...Level) {
 	configs.Default.StacktraceLevels = levels
 }
 
+// SetFatalActions sets the actions after FATAL and PANIC entries. Only affects loggers created after this call.
+func SetFatalActions(onFatal, onPanic FatalAction) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	configs.Default.OnFatal = onFatal
+	configs.Default.OnPanic = onPanic
+}
+
+// SetExitFunc sets the function replacing os.Exit for the exit action. Only affects loggers created after this call.
+func SetExitFunc(fn func(code int)) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	configs.Default.ExitFunc = fn
+}
+
+// SetEntryHandlers sets the default entry handlers. Only affects loggers created after this call.
+func SetEntryHandlers(handlers ...EntryHandler) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	configs.Default.EntryHandlers = handlers
+}
+
+// SetClock sets the default clock. Only affects loggers created after this call.
+func SetClock(clock Clock) {
+	rwmutex.Lock()
+	defer rwmutex.Unlock()
+	configs.Default.Clock = clock
+}
+
 // SetWriter sets the default writer. Only affects loggers created after this call.
 func SetWriter(writer io.Writer) {
 	rwmutex.Lock()
@@ -0,0 +1,191 @@
+package golog
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// GoroutinesFieldName is the field name used for the goroutine dump of diagnostics.
+	GoroutinesFieldName = "goroutines"
+	// ProcessFieldName is the field name used for the process metadata of diagnostics.
+	ProcessFieldName = "process"
+	// RecentEntriesFieldName is the field name used for the recent entries of diagnostics.
+	RecentEntriesFieldName = "recent"
+)
+
+// DiagnosticsConfig configures the diagnostics added to FATAL and PANIC entries.
+type DiagnosticsConfig struct {
+	// Goroutines adds the stacks of all goroutines.
+	Goroutines bool `json:"goroutines" yaml:"goroutines"`
+	// Process adds the pid, the uptime and memory statistics of the process.
+	Process bool `json:"process" yaml:"process"`
+	// RecentEntries adds up to this number of the last entries written by the logger.
+	RecentEntries int `json:"recentEntries" yaml:"recentEntries"`
+}
+
+func (c DiagnosticsConfig) enabled() bool {
+	return c.Goroutines || c.Process || c.RecentEntries > 0
+}
+
+// processStart approximates the start time of the process.
+var processStart = time.Now()
+
+// appendDiagnostics appends the diagnostic fields configured for l.
+func (l *Log) appendDiagnostics(fields []Field) []Field {
+	if l.diagnostics.Goroutines {
+		fields = append(fields, Array(GoroutinesFieldName, goroutineArray(parseGoroutines(goroutineDump()))))
+	}
+	if l.diagnostics.Process {
+		fields = append(fields, Object(ProcessFieldName, processObject{}))
+	}
+	if l.recent != nil {
+		fields = append(fields, Array(RecentEntriesFieldName, recentEntries{l.recent}))
+	}
+	return fields
+}
+
+// goroutineDump returns the stacks of all goroutines, as formatted by runtime.Stack.
+func goroutineDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// goroutine is a goroutine parsed from a dump.
+type goroutine struct {
+	id        int
+	state     string
+	frames    []runtime.Frame
+	createdBy *runtime.Frame
+}
+
+func (g goroutine) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt("id", g.id)
+	enc.AddString("state", g.state)
+	_ = enc.AddArray("frames", frameArray(g.frames))
+	if g.createdBy != nil {
+		_ = enc.AddObject("createdBy", frameObject(*g.createdBy))
+	}
+	return nil
+}
+
+type goroutineArray []goroutine
+
+func (a goroutineArray) MarshalLogArray(enc ArrayEncoder) error {
+	for i := range a {
+		_ = enc.AppendObject(a[i])
+	}
+	return nil
+}
+
+// parseGoroutines parses a dump formatted by runtime.Stack into goroutines.
+func parseGoroutines(dump []byte) []goroutine {
+	var gs []goroutine
+	for _, block := range bytes.Split(dump, []byte("\n\n")) {
+		lines := strings.Split(strings.TrimSpace(string(block)), "\n")
+		g, ok := parseGoroutineHeader(lines[0])
+		if !ok {
+			continue
+		}
+		for i := 1; i < len(lines); i++ {
+			fn := lines[i]
+			if strings.HasPrefix(fn, "...") {
+				continue
+			}
+			frame := runtime.Frame{}
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "\t") {
+				frame.File, frame.Line = parseFrameLocation(lines[i+1])
+				i++
+			}
+			if name, ok := strings.CutPrefix(fn, "created by "); ok {
+				if j := strings.Index(name, " in goroutine "); j >= 0 {
+					name = name[:j]
+				}
+				frame.Function = name
+				g.createdBy = &frame
+				continue
+			}
+			if j := strings.LastIndexByte(fn, '('); j > 0 {
+				fn = fn[:j]
+			}
+			frame.Function = fn
+			g.frames = append(g.frames, frame)
+		}
+		gs = append(gs, g)
+	}
+	return gs
+}
+
+// parseGoroutineHeader parses a line such as "goroutine 7 [chan receive, 2 minutes]:".
+func parseGoroutineHeader(line string) (goroutine, bool) {
+	rest, ok := strings.CutPrefix(line, "goroutine ")
+	if !ok {
+		return goroutine{}, false
+	}
+	id, state, ok := strings.Cut(rest, " [")
+	if !ok {
+		return goroutine{}, false
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return goroutine{}, false
+	}
+	return goroutine{id: n, state: strings.TrimSuffix(state, "]:")}, true
+}
+
+// parseFrameLocation parses a line such as "\t/src/main.go:12 +0x1d".
+func parseFrameLocation(line string) (string, int) {
+	loc := strings.TrimSpace(line)
+	if i := strings.LastIndex(loc, " +0x"); i >= 0 {
+		loc = loc[:i]
+	}
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return loc, 0
+	}
+	n, _ := strconv.Atoi(loc[i+1:])
+	return loc[:i], n
+}
+
+// processObject renders the metadata of the process.
+type processObject struct{}
+
+func (processObject) MarshalLogObject(enc ObjectEncoder) error {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	enc.AddInt("pid", os.Getpid())
+	enc.AddDuration("uptime", time.Since(processStart))
+	enc.AddInt("goroutines", runtime.NumGoroutine())
+	return enc.AddObject("memstats", ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddUint64("alloc", ms.Alloc)
+		enc.AddUint64("totalAlloc", ms.TotalAlloc)
+		enc.AddUint64("sys", ms.Sys)
+		enc.AddUint64("heapInuse", ms.HeapInuse)
+		enc.AddUint64("heapObjects", ms.HeapObjects)
+		enc.AddInt64("numGC", int64(ms.NumGC))
+		enc.AddDuration("pauseTotal", time.Duration(ms.PauseTotalNs))
+		return nil
+	}))
+}
+
+// recentEntries renders the entries kept by a RingBuffer, oldest first.
+type recentEntries struct {
+	rb *RingBuffer
+}
+
+func (r recentEntries) MarshalLogArray(enc ArrayEncoder) error {
+	r.rb.each(func(re *RingEntry) {
+		enc.AppendString(string(bytes.TrimRight(re.Data, "\n")))
+	})
+	return nil
+}
@@ -0,0 +1,109 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnostics(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("diag", golog.Config{
+		Encoding:    golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+		Diagnostics: golog.DiagnosticsConfig{Goroutines: true, Process: true, RecentEntries: 2},
+	})
+	require.NoError(err)
+
+	log.Info("first")
+	log.Info("second")
+	log.WithValues("a", 1).Warn("third")
+	buf.Reset()
+	require.PanicsWithValue("crash", func() { log.Panic("crash") })
+
+	var out struct {
+		Message    string `json:"message"`
+		Goroutines []struct {
+			ID     int    `json:"id"`
+			State  string `json:"state"`
+			Frames []struct {
+				Function string `json:"function"`
+				File     string `json:"file"`
+				Line     int    `json:"line"`
+			} `json:"frames"`
+			CreatedBy *struct {
+				Function string `json:"function"`
+			} `json:"createdBy"`
+		} `json:"goroutines"`
+		Process struct {
+			PID        int `json:"pid"`
+			Goroutines int `json:"goroutines"`
+			Memstats   struct {
+				Sys uint64 `json:"sys"`
+			} `json:"memstats"`
+		} `json:"process"`
+		Recent []string `json:"recent"`
+	}
+	require.NoError(json.Unmarshal(buf.Bytes(), &out), buf.String())
+	require.Equal("crash", out.Message)
+
+	require.NotEmpty(out.Goroutines)
+	current := out.Goroutines[0]
+	require.Equal("running", current.State)
+	require.NotNil(current.CreatedBy)
+	require.Equal("testing.(*T).Run", current.CreatedBy.Function)
+	found := false
+	for _, f := range current.Frames {
+		if f.Function == "github.com/millken/golog_test.TestDiagnostics" {
+			found = true
+			require.True(strings.HasSuffix(f.File, "diagnostics_test.go"))
+			require.NotZero(f.Line)
+		}
+	}
+	require.True(found, "test frame not found in %+v", current.Frames)
+
+	require.Equal(os.Getpid(), out.Process.PID)
+	require.NotZero(out.Process.Goroutines)
+	require.NotZero(out.Process.Memstats.Sys)
+
+	require.Equal([]string{
+		`{"level":"info","message":"second"}`,
+		`{"level":"warning","message":"third","a":1}`,
+	}, out.Recent)
+}
+
+func TestDiagnostics_NotForOtherLevels(t *testing.T) {
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("diag", golog.Config{
+		Encoding:    golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+		Diagnostics: golog.DiagnosticsConfig{Goroutines: true, Process: true, RecentEntries: 2},
+	})
+	require.NoError(t, err)
+	log.Error("failed")
+	require.Equal(t, `{"level":"error","message":"failed"}`+"\n", buf.String())
+}
+
+func TestPanic_FlushesFile(t *testing.T) {
+	path := t.TempDir() + "/panic.log"
+	log, err := golog.NewLoggerByConfig("diag", golog.Config{
+		Encoding:    golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeFile, File: golog.FileConfig{Path: path}},
+	})
+	require.NoError(t, err)
+	log.Info("buffered")
+	require.Panics(t, func() { log.Panic("crash") })
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, `{"level":"info","message":"buffered"}`+"\n"+`{"level":"panic","message":"crash"}`+"\n", string(b))
+}
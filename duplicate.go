@@ -0,0 +1,148 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DuplicateKeyPolicy defines what happens when several fields of an entry share a key.
+// Keys are compared among the fields of the same group only.
+type DuplicateKeyPolicy string
+
+const (
+	// DuplicateKeysKeep keeps every field, which may produce duplicate JSON keys. This is the default.
+	DuplicateKeysKeep DuplicateKeyPolicy = "keep"
+	// DuplicateKeysLastWins keeps the last field with a given key, at the place of the last one.
+	DuplicateKeysLastWins DuplicateKeyPolicy = "last"
+	// DuplicateKeysFirstWins keeps the first field with a given key.
+	DuplicateKeysFirstWins DuplicateKeyPolicy = "first"
+	// DuplicateKeysRename keeps every field, renaming repeated keys with a numeric suffix: key, key_1, key_2...
+	DuplicateKeysRename DuplicateKeyPolicy = "rename"
+)
+
+// droppedType marks a field removed by the duplicate key policy.
+// Its Integer holds the number of following fields it carries along (for groups).
+const droppedType = groupOpenType + 1
+
+func parseDuplicateKeyPolicy(p DuplicateKeyPolicy) (DuplicateKeyPolicy, error) {
+	switch p {
+	case "":
+		return DuplicateKeysKeep, nil
+	case DuplicateKeysKeep, DuplicateKeysLastWins, DuplicateKeysFirstWins, DuplicateKeysRename:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown duplicate keys policy: %s", p)
+}
+
+// dedupeFields applies policy to flattened fields and returns them, compacted in place.
+// It does not allocate unless keys are renamed.
+func dedupeFields(fields []Field, policy DuplicateKeyPolicy) []Field {
+	if policy == DuplicateKeysKeep || len(fields) < 2 {
+		return fields
+	}
+	if dedupeScope(fields, policy) {
+		fields = fields[:compactFields(fields)]
+	}
+	return fields
+}
+
+// dedupeScope applies policy to the sibling fields of one group level and recurses into groups.
+// It reports whether fields were dropped.
+func dedupeScope(fields []Field, policy DuplicateKeyPolicy) bool {
+	dropped := false
+	// suffixes holds the last suffix used for each renamed key.
+	var suffixes map[string]int
+	for i := 0; i < len(fields); i += fieldSpan(fields[i]) {
+		if fields[i].Type == GroupType {
+			if dedupeScope(groupFields(fields, i), policy) {
+				dropped = true
+			}
+		}
+		if fields[i].Type == droppedType {
+			continue
+		}
+		j := indexKey(fields[:i], fields[i].Key)
+		if j < 0 {
+			continue
+		}
+		switch policy {
+		case DuplicateKeysFirstWins:
+			dropField(&fields[i])
+			dropped = true
+		case DuplicateKeysLastWins:
+			for ; j >= 0; j = indexKey(fields[:i], fields[i].Key) {
+				dropField(&fields[j])
+			}
+			dropped = true
+		case DuplicateKeysRename:
+			if suffixes == nil {
+				suffixes = make(map[string]int)
+			}
+			key := fields[i].Key
+			n := suffixes[key]
+			for {
+				n++
+				fields[i].Key = key + "_" + strconv.Itoa(n)
+				if indexKey(fields[:i], fields[i].Key) < 0 {
+					break
+				}
+			}
+			suffixes[key] = n
+		}
+	}
+	return dropped
+}
+
+// indexKey returns the index of the sibling field of fields with key that was not dropped, or -1.
+func indexKey(fields []Field, key string) int {
+	for j := 0; j < len(fields); j += fieldSpan(fields[j]) {
+		if fields[j].Type != droppedType && fields[j].Key == key {
+			return j
+		}
+	}
+	return -1
+}
+
+// fieldSpan returns the number of flattened fields taken by f, including a group's fields.
+func fieldSpan(f Field) int {
+	switch f.Type {
+	case GroupType, droppedType, errorFieldsType:
+		return 1 + int(f.Integer)
+	}
+	return 1
+}
+
+func dropField(f *Field) {
+	if f.Type != GroupType {
+		f.Integer = 0
+	}
+	f.Type = droppedType
+}
+
+// compactFields removes dropped fields and error fields markers from fields in place,
+// fixing the size of the groups they belonged to, and returns the new length.
+func compactFields(fields []Field) int {
+	n := 0
+	for i := 0; i < len(fields); {
+		f := fields[i]
+		switch f.Type {
+		case droppedType:
+			i += fieldSpan(f)
+		case errorFieldsType:
+			i++
+		case GroupType:
+			children := groupFields(fields, i)
+			m := compactFields(children)
+			f.Integer = int64(m)
+			fields[n] = f
+			copy(fields[n+1:], children[:m])
+			n += 1 + m
+			i += 1 + len(children)
+		default:
+			fields[n] = f
+			n++
+			i++
+		}
+	}
+	return n
+}
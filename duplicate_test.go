@@ -0,0 +1,64 @@
+package golog_test
+
+import (
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		policy golog.DuplicateKeyPolicy
+		want   string
+	}{
+		{"", `"a":1,"b":2,"g":{"x":1,"x":2},"a":3,"g":4`},
+		{golog.DuplicateKeysKeep, `"a":1,"b":2,"g":{"x":1,"x":2},"a":3,"g":4`},
+		{golog.DuplicateKeysLastWins, `"b":2,"a":3,"g":4`},
+		{golog.DuplicateKeysFirstWins, `"a":1,"b":2,"g":{"x":1}`},
+		{golog.DuplicateKeysRename, `"a":1,"b":2,"g":{"x":1,"x_1":2},"a_1":3,"g_1":4`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var buf buffer.Buffer
+			log := newTestLogger(t, "dup", &buf, golog.Config{DuplicateKeys: tt.policy})
+			log.WithValues("a", 1, "b", 2).Info("dup", golog.Group("g", "x", 1, "x", 2), "a", 3, "g", 4)
+			require.Equal(t, `{"level":"info","message":"dup",`+tt.want+"}\n", buf.String())
+		})
+	}
+}
+
+func TestDuplicateKeys_Rename(t *testing.T) {
+	var buf buffer.Buffer
+	log := newTestLogger(t, "dup", &buf, golog.Config{DuplicateKeys: golog.DuplicateKeysRename})
+	log.Info("dup", "x", 1, "x", 2, "x", 3)
+	require.Equal(t, `{"level":"info","message":"dup","x":1,"x_1":2,"x_2":3}`+"\n", buf.String())
+
+	buf.Reset()
+	log.Info("taken", "x", 1, "x_1", 2, "x", 3, "x", 4)
+	require.Equal(t, `{"level":"info","message":"taken","x":1,"x_1":2,"x_2":3,"x_3":4}`+"\n", buf.String())
+}
+
+func TestDuplicateKeys_Groups(t *testing.T) {
+	var buf buffer.Buffer
+	log := newTestLogger(t, "dup", &buf, golog.Config{DuplicateKeys: golog.DuplicateKeysLastWins})
+	log.WithValues("id", 1).WithGroup("req").WithValues("id", 2, "path", "/").Info("scoped", "id", 3)
+	require.Equal(t, `{"level":"info","message":"scoped","id":1,"req":{"path":"/","id":3}}`+"\n", buf.String())
+
+	_, err := golog.NewLoggerByConfig("dup", golog.Config{DuplicateKeys: "merge"})
+	require.Error(t, err)
+}
+
+func TestDuplicateKeys_NoAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted under the race detector")
+	}
+	var buf buffer.Buffer
+	log := newTestLogger(t, "dup", &buf, golog.Config{DuplicateKeys: golog.DuplicateKeysLastWins})
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		log.LogFields(golog.INFO, "unique", golog.Int("a", 1), golog.Int("b", 2), golog.Int("c", 3))
+	})
+	require.Zero(t, allocs)
+}
@@ -1,6 +1,8 @@
 package golog
 
 import (
+	"fmt"
+	"math"
 	"net"
 	"sync/atomic"
 	"time"
@@ -28,7 +30,11 @@ var (
 
 	enc = json.Encoder{}
 
-	// ErrorMarshalFunc allows customization of global error marshaling
+	// ErrorMarshalFunc allows customization of global error marshaling.
+	// It is only used by encoders rendering errors with ErrorFormatMessage.
+	//
+	// Deprecated: ErrorMarshalFunc is a global variable that is racy to change.
+	// Use RegisterTypeEncoder[error] or Config.TypeEncoders instead.
 	ErrorMarshalFunc = func(err error) any {
 		return err
 	}
@@ -88,41 +94,65 @@ type appender interface {
 	AppendUints8(dst []byte, vals []uint8) []byte
 }
 
-func appendVal(dst []byte, value any) []byte {
+// appendField appends the value of f, using its unboxed storage when it has one.
+func appendField(dst []byte, f Field, types *TypeEncoders) []byte {
+	switch f.Type {
+	case StringType:
+		return enc.AppendString(dst, f.Str)
+	case Int64Type:
+		return enc.AppendInt64(dst, f.Integer)
+	case Uint64Type:
+		return enc.AppendUint64(dst, uint64(f.Integer))
+	case Float64Type:
+		return enc.AppendFloat64(dst, math.Float64frombits(uint64(f.Integer)))
+	case BoolType:
+		return enc.AppendBool(dst, f.Integer == 1)
+	case DurationType:
+		return enc.AppendDuration(dst, time.Duration(f.Integer), time.Millisecond, false)
+	case TimeType:
+		return enc.AppendTime(dst, f.timeValue(), TimeFieldFormat)
+	case StringerType:
+		return enc.AppendString(dst, f.Val.(fmt.Stringer).String())
+	case ObjectMarshalerType:
+		return appendObject(dst, f.Val.(ObjectMarshaler), types)
+	case ArrayMarshalerType:
+		return appendArray(dst, f.Val.(ArrayMarshaler), types)
+	}
+	return appendVal(dst, f.Val, types)
+}
+
+// appendError appends err as marshaled by ErrorMarshalFunc.
+func appendError(dst []byte, err error) []byte {
+	switch m := ErrorMarshalFunc(err).(type) {
+	case error:
+		if m == nil || isNilValue(m) {
+			return enc.AppendNil(dst)
+		}
+		return enc.AppendString(dst, m.Error())
+	case string:
+		return enc.AppendString(dst, m)
+	default:
+		return enc.AppendInterface(dst, m)
+	}
+}
+
+// appendVal appends value. Errors and the values of types without built-in rendering are
+// rendered by their type encoder in types or in the global registry when they have one.
+func appendVal(dst []byte, value any, types *TypeEncoders) []byte {
 	switch val := value.(type) {
 	case string:
 		dst = enc.AppendString(dst, val)
 	case []byte:
 		dst = enc.AppendBytes(dst, val)
 	case error:
-		switch m := ErrorMarshalFunc(val).(type) {
-		case error:
-			if m == nil || isNilValue(m) {
-				dst = enc.AppendNil(dst)
-			} else {
-				dst = enc.AppendString(dst, m.Error())
-			}
-		case string:
-			dst = enc.AppendString(dst, m)
-		default:
-			dst = enc.AppendInterface(dst, m)
+		if fn, ok := lookupTypeEncoder(types, val); ok {
+			return appendTypeEncoded(dst, fn, val, types)
 		}
+		dst = appendError(dst, val)
 	case []error:
 		dst = enc.AppendArrayStart(dst)
 		for i, err := range val {
-			switch m := ErrorMarshalFunc(err).(type) {
-			case error:
-				if m == nil || isNilValue(m) {
-					dst = enc.AppendNil(dst)
-				} else {
-					dst = enc.AppendString(dst, m.Error())
-				}
-			case string:
-				dst = enc.AppendString(dst, m)
-			default:
-				dst = enc.AppendInterface(dst, m)
-			}
-
+			dst = appendVal(dst, err, types)
 			if i < (len(val) - 1) {
 				dst = enc.AppendArrayDelim(dst)
 			}
@@ -293,7 +323,16 @@ func appendVal(dst []byte, value any) []byte {
 		dst = enc.AppendIPPrefix(dst, val)
 	case net.HardwareAddr:
 		dst = enc.AppendMACAddr(dst, val)
+	case SecretValue:
+		dst = enc.AppendString(dst, redactedMask)
+	case ObjectMarshaler:
+		dst = appendObject(dst, val, types)
+	case ArrayMarshaler:
+		dst = appendArray(dst, val, types)
 	default:
+		if fn, ok := lookupTypeEncoder(types, val); ok {
+			return appendTypeEncoded(dst, fn, val, types)
+		}
 		dst = enc.AppendInterface(dst, val)
 	}
 	return dst
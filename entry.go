@@ -2,7 +2,9 @@ package golog
 
 import (
 	"io"
+	"runtime"
 	"sync"
+	"time"
 )
 
 type Flag uint8
@@ -19,6 +21,8 @@ const (
 )
 
 type Entry struct {
+	// Time is the time of the entry, read once from the clock of the logger.
+	Time       time.Time
 	Module     string
 	Message    string
 	Data       []byte
@@ -27,6 +31,7 @@ type Entry struct {
 	fieldsLen  int
 	callerSkip int
 	caller     string
+	frame      runtime.Frame
 	flag       Flag
 }
 
@@ -91,6 +96,17 @@ func (e *Entry) GetCaller() string {
 	return e.caller
 }
 
+// CallerFrame returns the frame of the code that logged the entry.
+// It is only resolved for the entries passed to an EntryHandler.
+func (e *Entry) CallerFrame() runtime.Frame {
+	return e.frame
+}
+
+// NestedFields returns a copy of the fields of the entry, groups holding their fields in Val as built by Group.
+func (e *Entry) NestedFields() []Field {
+	return nestFields(e.Fields[:e.fieldsLen])
+}
+
 // SetFlag sets the flag.
 func (e *Entry) SetFlag(flag Flag) {
 	e.flag |= flag
@@ -103,6 +119,7 @@ func (e *Entry) HasFlag(flag Flag) bool {
 
 // Reset resets all entry fields to zero values.
 func (e *Entry) Reset() {
+	e.Time = time.Time{}
 	e.Module = ""
 	e.Message = ""
 	e.Level = 0
@@ -111,6 +128,7 @@ func (e *Entry) Reset() {
 	e.fieldsLen = 0
 	e.callerSkip = 0
 	e.caller = ""
+	e.frame = runtime.Frame{}
 	e.flag = 0
 }
 
@@ -132,6 +150,7 @@ func acquireEntry() *Entry {
 
 // releaseEntry releases the entry.
 func releaseEntry(e *Entry) {
+	e.Time = time.Time{}
 	e.Module = ""
 	e.Message = ""
 	e.Level = 0
@@ -140,6 +159,7 @@ func releaseEntry(e *Entry) {
 	e.fieldsLen = 0
 	e.callerSkip = 0
 	e.caller = ""
+	e.frame = runtime.Frame{}
 	e.flag = 0
 	entryPool.Put(e)
 }
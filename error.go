@@ -0,0 +1,189 @@
+package golog
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// ErrorFormat defines how encoders render error field values.
+type ErrorFormat string
+
+const (
+	// ErrorFormatMessage renders errors as their message, or with their type encoder when one is registered. This is the default.
+	ErrorFormatMessage ErrorFormat = "message"
+	// ErrorFormatStructured renders errors as an object holding the message, the concrete type,
+	// the wrapped chain, joined errors, the error's own fields and its stack trace.
+	ErrorFormatStructured ErrorFormat = "structured"
+)
+
+const (
+	errorMessageKey = "message"
+	errorTypeKey    = "type"
+	errorChainKey   = "chain"
+	errorJoinedKey  = "errors"
+	errorFieldsKey  = "fields"
+
+	// maxErrorDepth bounds the nesting of joined errors.
+	maxErrorDepth = 8
+)
+
+// StackTracer is implemented by errors that record the program counters of where they were created.
+// Errors whose StackTrace method returns a slice of another uintptr type, such as the
+// errors.StackTrace of github.com/pkg/errors, are supported as well.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// FramesTracer is implemented by errors that record the frames of where they were created.
+type FramesTracer interface {
+	Frames() []runtime.Frame
+}
+
+// fieldError returns the non-nil error held by f, if any.
+func fieldError(f Field) (error, bool) {
+	switch f.Type {
+	case ErrorType:
+		return f.Val.(error), true
+	case AnyType:
+		err, ok := f.Val.(error)
+		if !ok || isNilValue(err) {
+			return nil, false
+		}
+		return err, true
+	}
+	return nil, false
+}
+
+// errorObject renders an error structurally.
+type errorObject struct {
+	err   error
+	depth int
+	// chained is set for the members of a chain, which do not repeat the rest of it.
+	chained bool
+}
+
+func (o errorObject) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString(errorMessageKey, o.err.Error())
+	enc.AddString(errorTypeKey, reflect.TypeOf(o.err).String())
+	if m, ok := o.err.(ObjectMarshaler); ok {
+		_ = enc.AddObject(errorFieldsKey, m)
+	}
+	if o.depth < maxErrorDepth {
+		if joined, ok := o.err.(interface{ Unwrap() []error }); ok {
+			_ = enc.AddArray(errorJoinedKey, errorArray{errs: joined.Unwrap(), depth: o.depth + 1})
+		}
+	}
+	if o.chained {
+		return nil
+	}
+	if next := errors.Unwrap(o.err); next != nil {
+		_ = enc.AddArray(errorChainKey, errorChain{err: next, depth: o.depth})
+	}
+	if frames := errorFrames(o.err); len(frames) > 0 {
+		_ = enc.AddArray(ErrorStackFieldName, frameArray(frames))
+	}
+	return nil
+}
+
+// errorChain renders the errors.Unwrap chain starting at err.
+type errorChain struct {
+	err   error
+	depth int
+}
+
+func (c errorChain) MarshalLogArray(enc ArrayEncoder) error {
+	for err := c.err; err != nil; err = errors.Unwrap(err) {
+		_ = enc.AppendObject(errorObject{err: err, depth: c.depth, chained: true})
+	}
+	return nil
+}
+
+// errorArray renders the members of a joined error.
+type errorArray struct {
+	errs  []error
+	depth int
+}
+
+func (a errorArray) MarshalLogArray(enc ArrayEncoder) error {
+	for _, err := range a.errs {
+		if err == nil {
+			continue
+		}
+		_ = enc.AppendObject(errorObject{err: err, depth: a.depth})
+	}
+	return nil
+}
+
+// errorFrames returns the stack trace recorded by the deepest error of the chain of err that has one.
+func errorFrames(err error) []runtime.Frame {
+	var frames []runtime.Frame
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch st := err.(type) {
+		case FramesTracer:
+			if f := st.Frames(); len(f) > 0 {
+				frames = f
+			}
+		case StackTracer:
+			if pcs := st.StackTrace(); len(pcs) > 0 {
+				frames = framesFromPCs(pcs)
+			}
+		default:
+			if pcs := reflectStackTrace(err); len(pcs) > 0 {
+				frames = framesFromPCs(pcs)
+			}
+		}
+	}
+	return frames
+}
+
+// reflectStackTrace returns the program counters recorded by err if it has a StackTrace
+// method returning a slice of a uintptr type, as the errors of github.com/pkg/errors do.
+func reflectStackTrace(err error) []uintptr {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	typ := m.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 || typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
+
+func framesFromPCs(pcs []uintptr) []runtime.Frame {
+	frames := make([]runtime.Frame, 0, len(pcs))
+	it := runtime.CallersFrames(pcs)
+	for {
+		f, more := it.Next()
+		frames = append(frames, f)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// frameArray renders stack frames as objects with function, file and line keys.
+type frameArray []runtime.Frame
+
+func (a frameArray) MarshalLogArray(enc ArrayEncoder) error {
+	for i := range a {
+		_ = enc.AppendObject(frameObject(a[i]))
+	}
+	return nil
+}
+
+type frameObject runtime.Frame
+
+func (f frameObject) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("function", f.Function)
+	enc.AddString("file", f.File)
+	enc.AddInt("line", f.Line)
+	return nil
+}
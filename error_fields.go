@@ -0,0 +1,154 @@
+package golog
+
+// errorFieldsType marks the fields extracted from an error field; Integer holds their number.
+// The marker is removed, and its fields kept inline, once collisions are resolved.
+const errorFieldsType = droppedType + 1
+
+// fieldsError is an error carrying log fields.
+type fieldsError struct {
+	err    error
+	fields []Field
+}
+
+func (e *fieldsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldsError) Unwrap() error {
+	return e.err
+}
+
+// WrapError returns an error that wraps err and carries the fields described by keysAndVals.
+// When the error, or an error wrapping it, is logged as a field value, its fields are added
+// to the entry next to it. Fields logged explicitly win over the error's ones on key collision,
+// and the fields of outer errors win over the ones of the errors they wrap.
+// WrapError returns nil if err is nil.
+func WrapError(err error, keysAndVals ...any) error {
+	if err == nil {
+		return nil
+	}
+	fields, _ := appendFields(nil, keysAndVals, false)
+	return &fieldsError{err: err, fields: fields}
+}
+
+// ErrorFields returns the fields carried by err and the errors it wraps, outermost first.
+func ErrorFields(err error) []Field {
+	var fields []Field
+	walkErrorFields(err, 0, func(f []Field) {
+		fields = append(fields, f...)
+	})
+	return fields
+}
+
+// walkErrorFields calls fn with the fields of every fieldsError in the tree of err, outermost first.
+func walkErrorFields(err error, depth int, fn func([]Field)) {
+	for err != nil && depth < maxErrorDepth {
+		switch e := err.(type) {
+		case *fieldsError:
+			fn(e.fields)
+			err = e.err
+		case interface{ Unwrap() []error }:
+			for _, member := range e.Unwrap() {
+				walkErrorFields(member, depth+1, fn)
+			}
+			return
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return
+		}
+	}
+}
+
+// hasErrorFields reports whether err or an error it wraps carries fields.
+func hasErrorFields(err error) bool {
+	found := false
+	walkErrorFields(err, 0, func(f []Field) {
+		if len(f) > 0 {
+			found = true
+		}
+	})
+	return found
+}
+
+// appendErrorFields appends the fields carried by err behind an errorFieldsType marker.
+func appendErrorFields(dst []Field, err error) []Field {
+	i := len(dst)
+	dst = append(dst, Field{Type: errorFieldsType})
+	walkErrorFields(err, 0, func(fields []Field) {
+		for _, f := range fields {
+			dst = appendFlat(dst, f)
+		}
+	})
+	dst[i].Integer = int64(len(dst) - i - 1)
+	return dst
+}
+
+// mergeErrorFields resolves the key collisions of the fields extracted from errors and
+// removes their markers, compacting fields in place.
+func mergeErrorFields(fields []Field) []Field {
+	found := false
+	for i := range fields {
+		if fields[i].Type == errorFieldsType {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fields
+	}
+	mergeErrorFieldsScope(fields)
+	return fields[:compactFields(fields)]
+}
+
+// mergeErrorFieldsScope drops the extracted fields of one group level whose key is
+// already used by an explicit field or an earlier extracted field, and recurses into groups.
+func mergeErrorFieldsScope(fields []Field) {
+	for i := 0; i < len(fields); i += fieldSpan(fields[i]) {
+		switch fields[i].Type {
+		case GroupType:
+			mergeErrorFieldsScope(groupFields(fields, i))
+		case errorFieldsType:
+			extracted := fields[i+1 : i+1+int(fields[i].Integer)]
+			for k := 0; k < len(extracted); k += fieldSpan(extracted[k]) {
+				if extracted[k].Type == GroupType {
+					mergeErrorFieldsScope(groupFields(extracted, k))
+				}
+				if hasExplicitKey(fields, extracted[k].Key) || hasExtractedKeyBefore(fields, i+1+k, extracted[k].Key) {
+					dropField(&extracted[k])
+				}
+			}
+		}
+	}
+}
+
+// hasExplicitKey reports whether a field of the group level fields, not extracted from an error, has key.
+func hasExplicitKey(fields []Field, key string) bool {
+	for i := 0; i < len(fields); i += fieldSpan(fields[i]) {
+		switch fields[i].Type {
+		case errorFieldsType, droppedType:
+		default:
+			if fields[i].Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasExtractedKeyBefore reports whether a field extracted from an error and placed
+// before fields[end] at the group level fields has key.
+func hasExtractedKeyBefore(fields []Field, end int, key string) bool {
+	for i := 0; i < end; i += fieldSpan(fields[i]) {
+		if fields[i].Type != errorFieldsType {
+			continue
+		}
+		extracted := fields[i+1 : i+1+int(fields[i].Integer)]
+		for k := 0; k < len(extracted) && i+1+k < end; k += fieldSpan(extracted[k]) {
+			if extracted[k].Type != droppedType && extracted[k].Key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package golog_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapError(t *testing.T) {
+	require := require.New(t)
+	require.NoError(golog.WrapError(nil, "id", 1))
+
+	base := errors.New("not found")
+	inner := golog.WrapError(base, "table", "users", "id", 7)
+	outer := golog.WrapError(fmt.Errorf("load: %w", inner), "id", 8, golog.Int("attempt", 2))
+	require.Equal("load: not found", outer.Error())
+	require.ErrorIs(outer, base)
+	require.Equal([]golog.Field{
+		golog.Any("id", 8), golog.Int("attempt", 2),
+		golog.Any("table", "users"), golog.Any("id", 7),
+	}, golog.ErrorFields(outer))
+	require.Empty(golog.ErrorFields(base))
+	require.Len(golog.ErrorFields(errors.Join(base, inner)), 2)
+}
+
+func TestWrapError_Merge(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	inner := golog.WrapError(errors.New("not found"), "table", "users", "id", 7)
+	err := golog.WrapError(fmt.Errorf("load: %w", inner), "id", 8, "user", "bob")
+	golog.Error("failed", golog.Err(err), "user", "alice")
+	require.Equal(`{"level":"error","message":"failed","error":"load: not found","id":8,"table":"users","user":"alice"}`+"\n",
+		buf.String())
+
+	buf.Reset()
+	golog.Error("grouped", golog.Group("req", "err", err), "id", 1)
+	require.Equal(`{"level":"error","message":"grouped","req":{"err":"load: not found","id":8,"user":"bob","table":"users"},"id":1}`+"\n",
+		buf.String())
+
+	buf.Reset()
+	golog.Error("plain", "err", errors.New("boom"))
+	require.Equal(`{"level":"error","message":"plain","err":"boom"}`+"\n", buf.String())
+}
+
+func TestWrapError_Text(t *testing.T) {
+	defer resetConfigs()
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+
+	golog.WithGroup("job").Error("failed", "err", golog.WrapError(errors.New("timeout"), "retries", 3))
+	require.Equal(t, "ERRO failed job.err=timeout job.retries=3\n", buf.String())
+}
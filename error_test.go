@@ -0,0 +1,133 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+type testStackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newTestStackError(msg string) *testStackError {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(1, pcs)
+	return &testStackError{msg: msg, pcs: pcs[:n]}
+}
+
+func (e *testStackError) Error() string         { return e.msg }
+func (e *testStackError) StackTrace() []uintptr { return e.pcs }
+
+func (e *testStackError) MarshalLogObject(enc golog.ObjectEncoder) error {
+	enc.AddString("shard", "eu-1")
+	return nil
+}
+
+func TestErrorFormat_Structured_JSON(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true, ErrorFormat: golog.ErrorFormatStructured})
+
+	base := newTestStackError("disk full")
+	err := fmt.Errorf("save user: %w", base)
+	golog.Error("failed", golog.Err(err))
+	require.True(validJSON(buf.String()), buf.String())
+
+	var out struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Chain   []struct {
+				Message string            `json:"message"`
+				Type    string            `json:"type"`
+				Fields  map[string]string `json:"fields"`
+			} `json:"chain"`
+			Stack []struct {
+				Function string `json:"function"`
+				File     string `json:"file"`
+				Line     int    `json:"line"`
+			} `json:"stack"`
+		} `json:"error"`
+	}
+	require.NoError(json.Unmarshal(buf.Bytes(), &out))
+	require.Equal("save user: disk full", out.Error.Message)
+	require.Equal("*fmt.wrapError", out.Error.Type)
+	require.Len(out.Error.Chain, 1)
+	require.Equal("disk full", out.Error.Chain[0].Message)
+	require.Equal("*golog_test.testStackError", out.Error.Chain[0].Type)
+	require.Equal(map[string]string{"shard": "eu-1"}, out.Error.Chain[0].Fields)
+	require.NotEmpty(out.Error.Stack)
+	require.Contains(out.Error.Stack[0].Function, "newTestStackError")
+	require.Contains(out.Error.Stack[0].File, "error_test.go")
+
+	buf.Reset()
+	golog.Error("joined", "err", errors.Join(errors.New("a"), errors.New("b")), "plain", "x")
+	require.True(validJSON(buf.String()), buf.String())
+	require.Contains(buf.String(), `"err":{"message":"a\nb","type":"*errors.joinError","errors":[`+
+		`{"message":"a","type":"*errors.errorString"},{"message":"b","type":"*errors.errorString"}]},"plain":"x"`)
+}
+
+// pkgFrame and pkgStackTrace have the shape of the Frame and StackTrace of github.com/pkg/errors.
+type pkgFrame uintptr
+
+type pkgStackTrace []pkgFrame
+
+type pkgStackError struct {
+	pcs []uintptr
+}
+
+func (e *pkgStackError) Error() string { return "pkg" }
+
+func (e *pkgStackError) StackTrace() pkgStackTrace {
+	st := make(pkgStackTrace, len(e.pcs))
+	for i, pc := range e.pcs {
+		st[i] = pkgFrame(pc)
+	}
+	return st
+}
+
+func TestErrorFormat_Structured_PkgErrorsStack(t *testing.T) {
+	var buf buffer.Buffer
+	log := newTestLogger(t, "error", &buf, golog.Config{JSONEncoder: golog.JSONEncoderConfig{ErrorFormat: golog.ErrorFormatStructured}})
+	log.Error("failed", golog.Err(&pkgStackError{pcs: newTestStackError("").pcs}))
+	require.Contains(t, buf.String(), `"stack":[{"function":"github.com/millken/golog_test.newTestStackError"`)
+}
+
+func TestErrorFormat_Structured_Text(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{
+		DisableTimestamp: true,
+		DisableColor:     true,
+		ErrorFormat:      golog.ErrorFormatStructured,
+	})
+
+	golog.Error("failed", "err", fmt.Errorf("outer: %w", errors.New("inner")))
+	require.Equal(`ERRO failed err.message="outer: inner" err.type=*fmt.wrapError `+
+		`err.chain=[{"message":"inner","type":"*errors.errorString"}]`+"\n", buf.String())
+}
+
+func TestErrorFormat_Message(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	golog.Error("failed", golog.Err(fmt.Errorf("outer: %w", errors.New("inner"))))
+	require.Equal(`{"level":"error","message":"failed","error":"outer: inner"}`+"\n", buf.String())
+}
@@ -0,0 +1,171 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventDurationFieldName is the field name used for the duration of events.
+const EventDurationFieldName = "duration"
+
+// Event accumulates the fields of a unit of work, such as a request, and logs them as a
+// single entry when finished: a canonical log line. An Event is safe for concurrent use,
+// and all its methods do nothing on a nil Event, as returned by EventFromContext for a
+// context without one.
+type Event struct {
+	mu       sync.Mutex
+	logger   Logger
+	clock    Clock
+	msg      string
+	level    Level
+	start    time.Time
+	fields   []Field
+	finished bool
+}
+
+// NewEvent returns an Event logged with logger at INFO level, or the worst level recorded, with the message msg.
+// Its durations are measured with the Clock of logger.
+func NewEvent(logger Logger, msg string) *Event {
+	clock := loggerClock(logger)
+	return &Event{
+		logger: logger,
+		clock:  clock,
+		msg:    msg,
+		level:  INFO,
+		start:  clock.Now(),
+	}
+}
+
+type eventKey struct{}
+
+// ContextWithEvent returns a copy of ctx carrying ev.
+func ContextWithEvent(ctx context.Context, ev *Event) context.Context {
+	return context.WithValue(ctx, eventKey{}, ev)
+}
+
+// EventFromContext returns the Event carried by ctx, or nil.
+func EventFromContext(ctx context.Context) *Event {
+	ev, _ := ctx.Value(eventKey{}).(*Event)
+	return ev
+}
+
+// StartEvent returns a new Event and a copy of ctx carrying it.
+func StartEvent(ctx context.Context, logger Logger, msg string) (context.Context, *Event) {
+	ev := NewEvent(logger, msg)
+	return ContextWithEvent(ctx, ev), ev
+}
+
+// Add adds the fields described by keysAndVals, replacing the fields already added with the same keys.
+// Fields added once the event is finished are ignored, as are the updates of Inc and Time.
+func (ev *Event) Add(keysAndVals ...any) {
+	if ev == nil {
+		return
+	}
+	fields, _ := appendFields(nil, keysAndVals, false)
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	if ev.finished {
+		return
+	}
+	for _, f := range fields {
+		*ev.field(f.Key) = f
+	}
+}
+
+// Inc increments the counter name, logged as an integer field.
+func (ev *Event) Inc(name string) {
+	if ev == nil {
+		return
+	}
+	ev.mu.Lock()
+	defer ev.mu.Unlock()
+	if ev.finished {
+		return
+	}
+	f := ev.field(name)
+	if f.Type != Int64Type {
+		*f = Int64(name, 0)
+	}
+	f.Integer++
+}
+
+// Time starts the timer name and returns the function stopping it, as in
+//
+//	defer ev.Time("db")()
+//
+// The durations of a timer started several times add up.
+func (ev *Event) Time(name string) (stop func()) {
+	if ev == nil {
+		return func() {}
+	}
+	start := ev.clock.Now()
+	return func() {
+		d := ev.clock.Now().Sub(start)
+		ev.mu.Lock()
+		defer ev.mu.Unlock()
+		if ev.finished {
+			return
+		}
+		f := ev.field(name)
+		if f.Type != DurationType {
+			*f = Duration(name, 0)
+		}
+		f.Integer += int64(d)
+	}
+}
+
+// Escalate raises the level of the event to level if it is worse than the current one.
+// The event is logged at ERROR level at most.
+func (ev *Event) Escalate(level Level) {
+	if ev == nil {
+		return
+	}
+	if level < ERROR {
+		level = ERROR
+	}
+	ev.mu.Lock()
+	if level < ev.level {
+		ev.level = level
+	}
+	ev.mu.Unlock()
+}
+
+// Err adds err under ErrorFieldName and raises the level of the event to ERROR if err is not nil.
+func (ev *Event) Err(err error) {
+	if ev == nil || err == nil {
+		return
+	}
+	ev.Add(Err(err))
+	ev.Escalate(ERROR)
+}
+
+// Finish logs the event with the fields added and its duration. Later calls do nothing.
+func (ev *Event) Finish() {
+	if ev == nil {
+		return
+	}
+	ev.mu.Lock()
+	if ev.finished {
+		ev.mu.Unlock()
+		return
+	}
+	ev.finished = true
+	fields := make([]Field, len(ev.fields), len(ev.fields)+1)
+	copy(fields, ev.fields)
+	fields = append(fields, Duration(EventDurationFieldName, ev.clock.Now().Sub(ev.start)))
+	level := ev.level
+	ev.mu.Unlock()
+	logFieldsTo(ev.logger, level, ev.msg, fields)
+}
+
+// field returns the field added with key, appending it if there is none.
+func (ev *Event) field(key string) *Field {
+	for i := range ev.fields {
+		if ev.fields[i].Key == key {
+			return &ev.fields[i]
+		}
+	}
+	ev.fields = append(ev.fields, Field{Key: key})
+	return &ev.fields[len(ev.fields)-1]
+}
@@ -0,0 +1,155 @@
+package golog_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "event")
+
+	ctx, ev := golog.StartEvent(context.Background(), log, "request")
+	require.Same(ev, golog.EventFromContext(ctx))
+
+	ev.Add("user", "john", "status", 200)
+	ev.Add("status", 500)
+	stop := ev.Time("db")
+	time.Sleep(time.Millisecond)
+	stop()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			golog.EventFromContext(ctx).Inc("queries")
+		}()
+	}
+	wg.Wait()
+	ev.Finish()
+	ev.Finish()
+
+	entries := obs.All()
+	require.Len(entries, 1)
+	e := entries[0]
+	require.Equal(golog.INFO, e.Level)
+	require.Equal("request", e.Message)
+	require.Equal([]string{"user", "status", "db", "queries", golog.EventDurationFieldName}, fieldKeys(e.Fields))
+	require.Equal(500, e.FieldMap()["status"])
+	require.Equal(int64(10), e.FieldMap()["queries"])
+	require.GreaterOrEqual(e.FieldMap()["db"], time.Millisecond)
+	require.GreaterOrEqual(e.FieldMap()[golog.EventDurationFieldName], time.Millisecond)
+	require.Contains(e.Caller.File, "event_test.go")
+}
+
+func TestEvent_Escalate(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "event")
+
+	ev := golog.NewEvent(log, "job")
+	ev.Escalate(golog.DEBUG)
+	ev.Escalate(golog.WARNING)
+	ev.Finish()
+
+	ev = golog.NewEvent(log, "job")
+	ev.Err(errors.New("failed"))
+	ev.Escalate(golog.WARNING)
+	ev.Finish()
+
+	ev = golog.NewEvent(log, "job")
+	ev.Escalate(golog.FATAL)
+	ev.Finish()
+
+	entries := obs.All()
+	require.Equal(golog.WARNING, entries[0].Level)
+	require.Equal(golog.ERROR, entries[1].Level)
+	require.Equal("failed", entries[1].FieldMap()["error"].(error).Error())
+	require.Equal(golog.ERROR, entries[2].Level)
+}
+
+func TestEvent_Clock(t *testing.T) {
+	require := require.New(t)
+	clock := gologtest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	obs := gologtest.NewObserver()
+	log, err := golog.NewLoggerByConfig("event", golog.Config{
+		Handler:       golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: io.Discard},
+		EntryHandlers: []golog.EntryHandler{obs},
+		Clock:         clock,
+	})
+	require.NoError(err)
+
+	ev := golog.NewEvent(log, "job")
+	stop := ev.Time("db")
+	clock.Add(2 * time.Second)
+	stop()
+	clock.Add(time.Second)
+	ev.Finish()
+
+	fields := obs.All()[0].FieldMap()
+	require.Equal(2*time.Second, fields["db"])
+	require.Equal(3*time.Second, fields[golog.EventDurationFieldName])
+}
+
+func TestEvent_AfterFinish(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "event")
+
+	ev := golog.NewEvent(log, "job")
+	ev.Add("a", 1)
+	stop := ev.Time("t")
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ev.Finish()
+	}()
+	ev.Add("b", 2)
+	ev.Inc("n")
+	wg.Wait()
+	ev.Add("c", 3)
+	ev.Inc("n")
+	stop()
+
+	entries := obs.All()
+	require.Len(entries, 1)
+	require.NotContains(entries[0].FieldMap(), "c")
+	require.NotContains(entries[0].FieldMap(), "t")
+}
+
+func finishDeferred(log golog.Logger) {
+	ev := golog.NewEvent(log, "deferred")
+	defer ev.Finish()
+}
+
+func TestEvent_FinishDeferred(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "event")
+	finishDeferred(log)
+	require.Equal(t, "github.com/millken/golog_test.finishDeferred", obs.All()[0].Caller.Function)
+}
+
+func TestEvent_Nil(t *testing.T) {
+	ev := golog.EventFromContext(context.Background())
+	require.Nil(t, ev)
+	ev.Add("a", 1)
+	ev.Inc("n")
+	ev.Time("t")()
+	ev.Escalate(golog.ERROR)
+	ev.Err(errors.New("failed"))
+	ev.Finish()
+}
+
+func fieldKeys(fields []golog.Field) []string {
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return keys
+}
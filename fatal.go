@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// FatalAction defines what a logger does once it has written a FATAL or PANIC entry.
+type FatalAction string
+
+const (
+	// FatalActionExit exits the process with the configured exit code. This is the default for FATAL entries.
+	FatalActionExit FatalAction = "exit"
+	// FatalActionPanic panics with a *PanicError. PANIC entries panic with their message if no action is set.
+	FatalActionPanic FatalAction = "panic"
+	// FatalActionGoexit ends the calling goroutine with runtime.Goexit.
+	FatalActionGoexit FatalAction = "goexit"
+	// FatalActionNoop does nothing, the logging call returns.
+	FatalActionNoop FatalAction = "noop"
+)
+
+// PanicError is the value of the panics raised by FatalActionPanic.
+type PanicError struct {
+	Level   Level
+	Message string
+	// Fields holds the fields of the entry, groups nested.
+	Fields []Field
+}
+
+func (e *PanicError) Error() string {
+	return e.Message
+}
+
+// FatalHook is called with the entry of a FATAL or PANIC entry once written,
+// before the action of the logger. fields must not be retained.
+type FatalHook func(level Level, msg string, fields []Field)
+
+var fatalHooks struct {
+	mu    sync.RWMutex
+	hooks []FatalHook
+}
+
+// RegisterFatalHook registers h to be called for the FATAL and PANIC entries of every logger.
+func RegisterFatalHook(h FatalHook) {
+	fatalHooks.mu.Lock()
+	fatalHooks.hooks = append(fatalHooks.hooks, h)
+	fatalHooks.mu.Unlock()
+}
+
+// fatalOptions holds the fatal behaviour of a logger.
+type fatalOptions struct {
+	onFatal   FatalAction
+	onPanic   FatalAction
+	exitCode  int
+	exitFunc  func(code int)
+	panicFunc func(v any)
+	hooks     []FatalHook
+}
+
+func newFatalOptions(cfg Config) (*fatalOptions, error) {
+	for _, a := range []FatalAction{cfg.OnFatal, cfg.OnPanic} {
+		switch a {
+		case "", FatalActionExit, FatalActionPanic, FatalActionGoexit, FatalActionNoop:
+		default:
+			return nil, fmt.Errorf("unknown fatal action: %s", a)
+		}
+	}
+	o := &fatalOptions{
+		onFatal:   cfg.OnFatal,
+		onPanic:   cfg.OnPanic,
+		exitCode:  cfg.ExitCode,
+		exitFunc:  cfg.ExitFunc,
+		panicFunc: cfg.PanicFunc,
+		hooks:     slices.Clone(cfg.FatalHooks),
+	}
+	if o.onFatal == "" {
+		o.onFatal = FatalActionExit
+	}
+	if o.exitCode == 0 {
+		o.exitCode = 1
+	}
+	if o.exitFunc == nil {
+		o.exitFunc = os.Exit
+	}
+	if o.panicFunc == nil {
+		o.panicFunc = func(v any) { panic(v) }
+	}
+	return o, nil
+}
+
+// fatal runs the hooks, then the action configured for level.
+// fields are the flattened fields of the entry.
+func (o *fatalOptions) fatal(level Level, msg string, fields []Field) {
+	fatalHooks.mu.RLock()
+	hooks := fatalHooks.hooks
+	fatalHooks.mu.RUnlock()
+	nested := nestFields(fields)
+	for _, h := range hooks {
+		h(level, msg, nested)
+	}
+	for _, h := range o.hooks {
+		h(level, msg, nested)
+	}
+
+	action := o.onFatal
+	if level == PANIC {
+		action = o.onPanic
+	}
+	switch action {
+	case FatalActionExit:
+		if err := Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "golog: failed to flush log: %v\n", err)
+		}
+		o.exitFunc(o.exitCode)
+	case FatalActionPanic:
+		o.panicFunc(&PanicError{Level: level, Message: msg, Fields: nested})
+	case FatalActionGoexit:
+		runtime.Goexit()
+	case FatalActionNoop:
+	default:
+		o.panicFunc(msg)
+	}
+}
+
+// nestFields turns flattened fields back into fields holding their groups' fields.
+func nestFields(fields []Field) []Field {
+	nested := make([]Field, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f.Type == GroupType {
+			children := groupFields(fields, i)
+			i += len(children)
+			f = Field{Key: f.Key, Type: GroupType, Val: nestFields(children)}
+		}
+		nested = append(nested, f)
+	}
+	return nested
+}
@@ -0,0 +1,169 @@
+package golog_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFatal_ExitFunc(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	code := 0
+	log := newTestLogger(t, "fatal", &buf, golog.Config{ExitCode: 3, ExitFunc: func(c int) { code = c }})
+
+	log.Fatal("boom", "a", 1)
+	require.Equal(3, code)
+	require.Equal(`{"level":"fatal","message":"boom","a":1}`+"\n", buf.String())
+
+	code = 0
+	log.Fatalf("boom %d", 2)
+	require.Equal(3, code)
+	code = 0
+	log.LogFields(golog.FATAL, "boom")
+	require.Equal(3, code)
+}
+
+func TestFatal_ExitFlushesFiles(t *testing.T) {
+	require := require.New(t)
+	path := filepath.Join(t.TempDir(), "file.log")
+	other := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{Type: golog.HandlerTypeFile, File: golog.FileConfig{Path: path}}})
+	defer other.Close()
+	other.Info("buffered")
+	require.Empty(readFile(t, path))
+
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{ExitFunc: func(int) {
+		require.Equal(`{"level":"info","message":"buffered"}`+"\n", readFile(t, path))
+	}})
+	log.Fatal("boom")
+}
+
+func TestPanic_PanicError(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{OnFatal: golog.FatalActionPanic, OnPanic: golog.FatalActionPanic})
+
+	v := catchPanic(func() { log.WithGroup("req").Fatal("boom", "id", 7) })
+	err, ok := v.(*golog.PanicError)
+	require.True(ok)
+	require.Equal(golog.FATAL, err.Level)
+	require.Equal("boom", err.Message)
+	require.Equal("boom", err.Error())
+	require.Len(err.Fields, 1)
+	require.Equal("req", err.Fields[0].Key)
+	require.Equal([]golog.Field{golog.Any("id", 7)}, err.Fields[0].Val)
+
+	v = catchPanic(func() { log.Panicf("boom %d", 2) })
+	require.IsType(&golog.PanicError{}, v)
+	require.Equal(golog.PANIC, v.(*golog.PanicError).Level)
+}
+
+func TestPanic_PanicErrorRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{
+		OnPanic: golog.FatalActionPanic,
+		Redact:  golog.RedactConfig{Presets: []string{"email"}},
+	})
+	v := catchPanic(func() { log.Panic("no user bob@example.com") })
+	require.Equal(t, "no user ***", v.(*golog.PanicError).Message)
+}
+
+func TestPanic_Default(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{})
+	require.Equal(t, "boom", catchPanic(func() { log.Panic("boom") }))
+}
+
+func TestFatal_PanicFunc(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	var got any
+	log := newTestLogger(t, "fatal", &buf, golog.Config{
+		OnFatal:   golog.FatalActionPanic,
+		PanicFunc: func(v any) { got = v },
+	})
+	log.Fatal("boom")
+	require.IsType(&golog.PanicError{}, got)
+	log.Panic("boom")
+	require.Equal("boom", got)
+}
+
+func TestFatal_Goexit(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{OnFatal: golog.FatalActionGoexit})
+	returned := false
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Fatal("boom")
+		returned = true
+	}()
+	wg.Wait()
+	require.False(t, returned)
+	require.Contains(t, buf.String(), "boom")
+}
+
+func TestFatal_Noop(t *testing.T) {
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{OnFatal: golog.FatalActionNoop, OnPanic: golog.FatalActionNoop})
+	log.Fatal("one")
+	log.Panic("two")
+	require.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestFatal_Hooks(t *testing.T) {
+	require := require.New(t)
+	var calls []string
+	golog.RegisterFatalHook(func(level golog.Level, msg string, fields []golog.Field) {
+		if msg == "hooked" {
+			calls = append(calls, "global")
+		}
+	})
+	var buf bytes.Buffer
+	log := newTestLogger(t, "fatal", &buf, golog.Config{
+		OnFatal: golog.FatalActionNoop,
+		FatalHooks: []golog.FatalHook{func(level golog.Level, msg string, fields []golog.Field) {
+			require.Equal(golog.FATAL, level)
+			require.Equal([]golog.Field{golog.Any("a", 1)}, fields)
+			require.Contains(buf.String(), msg)
+			calls = append(calls, "logger")
+		}},
+		ExitFunc: func(int) { calls = append(calls, "exit") },
+	})
+	log.Fatal("hooked", "a", 1)
+	require.Equal([]string{"global", "logger"}, calls)
+}
+
+func TestFatal_InvalidAction(t *testing.T) {
+	_, err := golog.NewLoggerByConfig("fatal", golog.Config{OnPanic: "abort"})
+	require.Error(t, err)
+}
+
+func TestSetFatalActions(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf bytes.Buffer
+	golog.SetWriter(&buf)
+	golog.SetFatalActions(golog.FatalActionExit, golog.FatalActionPanic)
+	code := 0
+	golog.SetExitFunc(func(c int) { code = c })
+
+	configs := golog.GetConfigs()
+	require.Equal(golog.FatalActionPanic, configs.Default.OnPanic)
+
+	golog.Fatal("boom")
+	require.Equal(1, code)
+	require.IsType(&golog.PanicError{}, catchPanic(func() { golog.Panic("boom") }))
+}
+
+func catchPanic(fn func()) (v any) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
@@ -0,0 +1,266 @@
+package golog
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// FieldType indicates which member of a Field holds its value.
+type FieldType uint8
+
+const (
+	// AnyType means the value is held in Val and encoded by type inspection.
+	AnyType FieldType = iota
+	// StringType means the value is held in Str.
+	StringType
+	// Int64Type means the value is held in Integer.
+	Int64Type
+	// Uint64Type means the value is held in Integer as its two's complement bits.
+	Uint64Type
+	// Float64Type means the value is held in Integer as IEEE 754 bits.
+	Float64Type
+	// BoolType means the value is held in Integer as 1 or 0.
+	BoolType
+	// DurationType means the value is held in Integer as nanoseconds.
+	DurationType
+	// TimeType means the value is held in Integer as Unix nanoseconds and Val as its *time.Location.
+	TimeType
+	// ErrorType means the value is an error held in Val.
+	ErrorType
+	// StringerType means the value is a fmt.Stringer held in Val.
+	StringerType
+	// ObjectMarshalerType means the value is an ObjectMarshaler held in Val.
+	ObjectMarshalerType
+	// ArrayMarshalerType means the value is an ArrayMarshaler held in Val.
+	ArrayMarshalerType
+	// GroupType means the field is a named group of fields. A field built by Group
+	// holds its []Field in Val; in Entry.Fields groups are flattened and Integer
+	// holds the number of following fields that belong to the group.
+	GroupType
+
+	// groupOpenType marks a group opened by WithGroup, which extends to the end of the fields.
+	groupOpenType
+)
+
+// Field is a key/value pair.
+//
+// Fields built with the typed constructors (String, Int64, ...) keep their value
+// unboxed and are encoded without inspecting its dynamic type. They can be passed
+// anywhere key/value pairs are accepted, taking the place of a key and its value.
+// Passed that way each Field is boxed into an interface, which allocates; use
+// LogFields to log typed fields without allocating.
+type Field struct {
+	Key     string
+	Val     any
+	Type    FieldType
+	Integer int64
+	Str     string
+}
+
+// String constructs a field with the given key and string value.
+func String(key string, val string) Field {
+	return Field{Key: key, Type: StringType, Str: val}
+}
+
+// Int64 constructs a field with the given key and int64 value.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Type: Int64Type, Integer: val}
+}
+
+// Int constructs a field with the given key and int value.
+func Int(key string, val int) Field {
+	return Int64(key, int64(val))
+}
+
+// Uint64 constructs a field with the given key and uint64 value.
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Type: Uint64Type, Integer: int64(val)}
+}
+
+// Float64 constructs a field with the given key and float64 value.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Type: Float64Type, Integer: int64(math.Float64bits(val))}
+}
+
+// Bool constructs a field with the given key and bool value.
+func Bool(key string, val bool) Field {
+	var i int64
+	if val {
+		i = 1
+	}
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration constructs a field with the given key and time.Duration value.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(val)}
+}
+
+// Time constructs a field with the given key and time.Time value.
+// Times that cannot be represented as Unix nanoseconds fall back to Any.
+func Time(key string, val time.Time) Field {
+	if val.Before(minTimeInt64) || val.After(maxTimeInt64) {
+		return Any(key, val)
+	}
+	return Field{Key: key, Type: TimeType, Integer: val.UnixNano(), Val: val.Location()}
+}
+
+// Err constructs a field with the key ErrorFieldName and the given error.
+// A nil error produces a field encoded as null.
+func Err(err error) Field {
+	return NamedErr(ErrorFieldName, err)
+}
+
+// NamedErr constructs a field with the given key and error.
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Any(key, nil)
+	}
+	return Field{Key: key, Type: ErrorType, Val: err}
+}
+
+// Stringer constructs a field with the given key and the output of the value's String method,
+// which is only called if the entry is encoded.
+func Stringer(key string, val fmt.Stringer) Field {
+	if val == nil {
+		return Any(key, nil)
+	}
+	return Field{Key: key, Type: StringerType, Val: val}
+}
+
+// Any constructs a field with the given key and an arbitrary value.
+func Any(key string, val any) Field {
+	return Field{Key: key, Val: val}
+}
+
+var (
+	minTimeInt64 = time.Unix(0, math.MinInt64)
+	maxTimeInt64 = time.Unix(0, math.MaxInt64)
+)
+
+// field is a shortcut to create Field.
+func field(k string, v any) Field {
+	return Field{Key: k, Val: v}
+}
+
+// timeValue returns the time held by a TimeType field.
+func (f Field) timeValue() time.Time {
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Val.(*time.Location); ok && loc != nil {
+		t = t.In(loc)
+	}
+	return t
+}
+
+// Value returns the value of the field as a Go value: a string, an int64, a uint64, a float64,
+// a bool, a time.Duration or a time.Time for the typed fields, and Val for the others.
+func (f Field) Value() any {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case Int64Type:
+		return f.Integer
+	case Uint64Type:
+		return uint64(f.Integer)
+	case Float64Type:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.timeValue()
+	}
+	return f.Val
+}
+
+// Group constructs a field that nests the fields described by keysAndVals under name.
+// JSONEncoder renders it as an object and TextEncoder as dotted keys; groups without
+// any field are omitted.
+func Group(name string, keysAndVals ...any) Field {
+	children, _ := appendFields([]Field{}, keysAndVals, false)
+	return Field{Key: name, Type: GroupType, Val: children}
+}
+
+// appendFields appends the fields described by keysAndVals to dst.
+// Field values are taken as they are; anything else is consumed as a key/value pair.
+// It stops at the first non-string key or a trailing key without a value, and
+// returns the index of the first unconsumed element.
+// If flatten is true, the fields are appended as by appendFlat.
+func appendFields(dst []Field, keysAndVals []any, flatten bool) ([]Field, int) {
+	i := 0
+	for i < len(keysAndVals) {
+		var f Field
+		if v, ok := keysAndVals[i].(Field); ok {
+			f = v
+			i++
+		} else {
+			if i+1 >= len(keysAndVals) {
+				break
+			}
+			key, isString := keysAndVals[i].(string)
+			if !isString {
+				break
+			}
+			f = field(key, keysAndVals[i+1])
+			i += 2
+		}
+		if flatten {
+			dst = appendFlat(dst, f)
+		} else {
+			dst = append(dst, f)
+		}
+	}
+	return dst, i
+}
+
+// appendFlat appends f to dst as it is emitted: LogValuer values are resolved
+// and groups built by Group are flattened into dst.
+func appendFlat(dst []Field, f Field) []Field {
+	if f.Type == AnyType {
+		if v, ok := f.Val.(LogValuer); ok {
+			f = resolveField(f.Key, v)
+		}
+	}
+	if f.Type != GroupType {
+		dst = append(dst, f)
+		if err, ok := fieldError(f); ok && hasErrorFields(err) {
+			dst = appendErrorFields(dst, err)
+		}
+		return dst
+	}
+	children, _ := f.Val.([]Field)
+	i := len(dst)
+	dst = append(dst, Field{Key: f.Key, Type: GroupType})
+	for _, c := range children {
+		dst = appendFlat(dst, c)
+	}
+	dst[i].Integer = int64(len(dst) - i - 1)
+	return dst
+}
+
+// closeGroups turns the groups opened by WithGroup into groups that extend to the end of fields.
+func closeGroups(fields []Field) {
+	for i := range fields {
+		if fields[i].Type == groupOpenType {
+			fields[i].Type = GroupType
+			fields[i].Integer = int64(len(fields) - i - 1)
+		}
+	}
+}
+
+// groupFields returns the fields that belong to the flattened group at fields[i].
+func groupFields(fields []Field, i int) []Field {
+	return fields[i+1 : i+1+int(fields[i].Integer)]
+}
+
+// isEmptyGroup reports whether the flattened group fields holds no field other than groups.
+func isEmptyGroup(fields []Field) bool {
+	for _, f := range fields {
+		if f.Type != GroupType {
+			return false
+		}
+	}
+	return true
+}
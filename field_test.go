@@ -0,0 +1,188 @@
+package golog_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func typedFields() []any {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []any{
+		golog.String("s", "hello world"),
+		golog.Int64("i", -42),
+		golog.Int("n", 7),
+		golog.Uint64("u", 1<<63),
+		golog.Float64("f", 1.5),
+		golog.Bool("b", true),
+		golog.Duration("d", 1500*time.Millisecond),
+		golog.Time("t", ts),
+		golog.Err(errors.New("boom")),
+		golog.Stringer("ip", net.IPv4(127, 0, 0, 1)),
+		golog.Any("m", map[string]int{"a": 1}),
+	}
+}
+
+func TestTypedFields_JSON(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	golog.Info("typed", typedFields()...)
+	require.True(validJSON(buf.String()))
+	require.Equal(`{"level":"info","message":"typed","s":"hello world","i":-42,"n":7,"u":9223372036854775808,`+
+		`"f":1.5,"b":true,"d":1500,"t":"2024-01-02T03:04:05Z","error":"boom","ip":"127.0.0.1","m":{"a":1}}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.LogFields(golog.WARNING, "fields", golog.String("s", "x"), golog.Bool("b", false))
+	require.Equal(`{"level":"warning","message":"fields","s":"x","b":false}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.WithValues(golog.Int("a", 1), "b", 2).Info("mixed", golog.Err(nil), "c", 3)
+	require.Equal(`{"level":"info","message":"mixed","a":1,"b":2,"error":null,"c":3}`+"\n", buf.String())
+}
+
+func TestTypedFields_Text(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+
+	golog.Info("typed", typedFields()...)
+	require.Equal(`INFO typed s="hello world" i=-42 n=7 u=9223372036854775808 f=1.5 b=true d=1.5s `+
+		`t=2024-01-02T03:04:05Z error=boom ip=127.0.0.1 m={"a":1}`+"\n", buf.String())
+}
+
+// plainLogger hides the FieldLogger methods of the logger it embeds.
+type plainLogger struct {
+	golog.Logger
+}
+
+func TestLogFieldsTo(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	var logger golog.Logger = plainLogger{golog.New("plain")}
+	_, ok := logger.(golog.FieldLogger)
+	require.False(ok)
+	golog.LogFieldsTo(logger, golog.WARNING, "plain", golog.Int("a", 1), golog.String("b", "x"))
+	require.Equal(`{"level":"warning","message":"plain","a":1,"b":"x"}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.LogFieldsTo(golog.New("typed"), golog.ERROR, "typed", golog.Bool("c", true))
+	require.Equal(`{"level":"error","message":"typed","c":true}`+"\n", buf.String())
+}
+
+func TestTypedFields_Time(t *testing.T) {
+	require := require.New(t)
+	loc := time.FixedZone("X", 3600)
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, loc)
+	f := golog.Time("t", ts)
+	require.Equal(golog.TimeType, f.Type)
+	require.Equal(loc, f.Val)
+
+	f = golog.Time("t", time.Time{})
+	require.Equal(golog.AnyType, f.Type)
+	require.Equal(time.Time{}, f.Val)
+}
+
+func BenchmarkLogText_WithTypedField(b *testing.B) {
+	require := require.New(b)
+	cfg := golog.Config{
+		Level:    golog.INFO,
+		Encoding: golog.TextEncoding,
+		TextEncoder: golog.TextEncoderConfig{
+			DisableTimestamp: fakeDisableTimestamp,
+			DisableColor:     fakeDisableColor,
+		},
+		Handler: golog.HandlerConfig{
+			Type: golog.HandlerTypeFile,
+			File: golog.FileConfig{
+				Path: "",
+			},
+		},
+	}
+	log, err := golog.NewLoggerByConfig("test5", cfg)
+	require.NoError(err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.LogFields(golog.INFO, "The quick brown fox jumps over the lazy dog",
+			golog.Int("a", 1),
+			golog.Bool("b", true),
+			golog.Float64("c", 1.234),
+		)
+	}
+}
+
+func BenchmarkLogJSON_WithTypedField(b *testing.B) {
+	require := require.New(b)
+	cfg := golog.Config{
+		Level:    golog.INFO,
+		Encoding: golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{
+			DisableTimestamp: fakeDisableTimestamp,
+		},
+		Handler: golog.HandlerConfig{
+			Type: golog.HandlerTypeFile,
+			File: golog.FileConfig{
+				Path: "",
+			},
+		},
+	}
+	log, err := golog.NewLoggerByConfig("test6", cfg)
+	require.NoError(err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.LogFields(golog.INFO, "The quick brown fox jumps over the lazy dog",
+			golog.Int("a", 1),
+			golog.Bool("b", true),
+			golog.Float64("c", 1.234),
+		)
+	}
+}
+
+func BenchmarkLogJSON_WithTypedFieldArgs(b *testing.B) {
+	require := require.New(b)
+	cfg := golog.Config{
+		Level:    golog.INFO,
+		Encoding: golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{
+			DisableTimestamp: fakeDisableTimestamp,
+		},
+		Handler: golog.HandlerConfig{
+			Type: golog.HandlerTypeFile,
+			File: golog.FileConfig{
+				Path: "",
+			},
+		},
+	}
+	log, err := golog.NewLoggerByConfig("test7", cfg)
+	require.NoError(err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("The quick brown fox jumps over the lazy dog",
+			golog.Int("a", 1000),
+			golog.Bool("b", true),
+			golog.Float64("c", 1.234),
+		)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"io"
 	"os"
+	"sync"
 )
 
 var (
@@ -11,14 +12,21 @@ var (
 )
 
 // File is an implementation of io.Writer interface.
+// It is safe for concurrent use.
 type File struct {
 	cfg    FileConfig
 	writer io.Writer
+	// file is the file opened for cfg.Path, nil for stdout, stderr and discard.
+	file *os.File
+	mu   sync.Mutex
+	stop chan struct{}
 }
 
 // NewFile creates and returns a new File writer.
+// Files opened for a path are buffered and registered for Sync and Close.
 func NewFile(cfg FileConfig) (*File, error) {
 	var writer io.Writer
+	var file *os.File
 	switch cfg.Path {
 	case "stdout":
 		writer = os.Stdout
@@ -31,33 +39,75 @@ func NewFile(cfg FileConfig) (*File, error) {
 		if err != nil {
 			return nil, err
 		}
+		file = f
 		writer = bufio.NewWriterSize(f, 4096)
 	}
 
-	return &File{
+	w := &File{
 		cfg:    cfg,
 		writer: writer,
-	}, nil
+		file:   file,
+	}
+	if file != nil {
+		if cfg.FlushInterval > 0 {
+			w.stop = make(chan struct{})
+			go flushEvery(cfg.FlushInterval, w.stop, w.Flush)
+		}
+		registerWriter(w)
+	}
+	return w, nil
 }
 
 // Write writes the contents of b to the file.
 func (w *File) Write(b []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	return w.writer.Write(b)
 }
 
 // Flush flushes any buffered data to the underlying writer.
 func (w *File) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+func (w *File) flush() error {
 	if f, ok := w.writer.(*bufio.Writer); ok {
 		return f.Flush()
 	}
 	return nil
 }
 
-// Close flushes any buffered data and closes the underlying writer if it implements io.Closer.
-func (w *File) Close() error {
-	_ = w.Flush()
-	if c, ok := w.writer.(io.Closer); ok {
-		return c.Close()
+// Sync flushes any buffered data and commits the file to stable storage.
+func (w *File) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.file != nil {
+		return w.file.Sync()
 	}
 	return nil
 }
+
+// Close flushes any buffered data and closes the file opened for the path.
+// Standard output and error are left open.
+func (w *File) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+	err := w.flush()
+	if w.file != nil {
+		unregisterWriter(w)
+		if cerr := w.file.Close(); err == nil {
+			err = cerr
+		}
+		w.file = nil
+	}
+	return err
+}
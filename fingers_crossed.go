@@ -0,0 +1,106 @@
+package golog
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// defaultFingersCrossedBufferSize is the number of entries buffered if FingersCrossedConfig.BufferSize is not set.
+const defaultFingersCrossedBufferSize = 100
+
+// FingersCrossedConfig configures the buffering of the entries less severe than a trigger level.
+// Such entries are kept in a bounded buffer, dropping the oldest ones, instead of being written.
+// An entry at or above the trigger level writes the buffer before itself, then buffering resumes,
+// or with Passthrough, the entries that follow are written as they come. The entries still buffered
+// at the end of the scope are discarded. A logger is a scope of its own, and Log.NewScope starts
+// new ones, e.g. per request.
+type FingersCrossedConfig struct {
+	// TriggerLevel is the level flushing the buffer. Buffering is disabled if not set.
+	TriggerLevel Level `json:"triggerLevel" yaml:"triggerLevel"`
+	// BufferSize is the maximum number of entries buffered, 100 if not set.
+	BufferSize int `json:"bufferSize" yaml:"bufferSize"`
+	// Passthrough stops buffering for the rest of the scope once triggered.
+	Passthrough bool `json:"passthrough" yaml:"passthrough"`
+}
+
+// fingersCrossed is the buffer of a scope.
+type fingersCrossed struct {
+	mu          sync.Mutex
+	trigger     Level
+	passthrough bool
+	entries     [][]byte
+	next        int
+	n           int
+	triggered   bool
+}
+
+func newFingersCrossed(cfg FingersCrossedConfig) *fingersCrossed {
+	if cfg.TriggerLevel == 0 {
+		return nil
+	}
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = defaultFingersCrossedBufferSize
+	}
+	return &fingersCrossed{trigger: cfg.TriggerLevel, passthrough: cfg.Passthrough, entries: make([][]byte, size)}
+}
+
+// write writes the entry b of level to w, or buffers it until the trigger level is reached.
+func (fc *fingersCrossed) write(w io.Writer, level Level, b []byte) error {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.triggered {
+		_, err := w.Write(b)
+		return err
+	}
+	if level > fc.trigger {
+		fc.entries[fc.next] = append(fc.entries[fc.next][:0], b...)
+		fc.next = (fc.next + 1) % len(fc.entries)
+		fc.n = min(fc.n+1, len(fc.entries))
+		return nil
+	}
+	fc.triggered = fc.passthrough
+	start := fc.next - fc.n
+	if start < 0 {
+		start += len(fc.entries)
+	}
+	for i := 0; i < fc.n; i++ {
+		if _, err := w.Write(fc.entries[(start+i)%len(fc.entries)]); err != nil {
+			return err
+		}
+	}
+	fc.n = 0
+	_, err := w.Write(b)
+	return err
+}
+
+// discard drops the buffered entries.
+func (fc *fingersCrossed) discard() {
+	fc.mu.Lock()
+	fc.n = 0
+	fc.mu.Unlock()
+}
+
+// NewScope returns a logger like l buffering its entries apart from l, as configured by
+// Config.FingersCrossed, and the function ending the scope. Without that config, the
+// returned logger writes as l does.
+func (l *Log) NewScope() (*Log, func()) {
+	scoped := l.clone()
+	if l.crossed == nil {
+		return scoped, func() {}
+	}
+	scoped.crossed = &fingersCrossed{
+		trigger:     l.crossed.trigger,
+		passthrough: l.crossed.passthrough,
+		entries:     make([][]byte, len(l.crossed.entries)),
+	}
+	return scoped, scoped.crossed.discard
+}
+
+// StartScope starts a new scope of l, as by Log.NewScope, and returns a copy of ctx
+// carrying the scoped logger, to be retrieved with LoggerFromContext.
+func StartScope(ctx context.Context, l *Log) (context.Context, func()) {
+	scoped, end := l.NewScope()
+	return ContextWithLogger(ctx, scoped), end
+}
@@ -0,0 +1,102 @@
+package golog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingersCrossed(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	log := newTestLogger(t, "crossed", &buf, golog.Config{
+		Level:          golog.DEBUG,
+		FingersCrossed: golog.FingersCrossedConfig{TriggerLevel: golog.ERROR, BufferSize: 2},
+	})
+
+	log.Debug("one")
+	log.Info("two")
+	log.WithValues("a", 1).Warn("three")
+	require.Empty(buf.String())
+
+	log.Error("four")
+	require.Equal(`{"level":"info","message":"two"}`+"\n"+
+		`{"level":"warning","message":"three","a":1}`+"\n"+
+		`{"level":"error","message":"four"}`+"\n", buf.String())
+
+	buf.Reset()
+	log.Debug("five")
+	require.Empty(buf.String())
+	log.Error("six")
+	require.Equal(`{"level":"debug","message":"five"}`+"\n"+`{"level":"error","message":"six"}`+"\n", buf.String())
+}
+
+func TestFingersCrossed_Passthrough(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	log := newTestLogger(t, "crossed", &buf, golog.Config{
+		Level:          golog.DEBUG,
+		FingersCrossed: golog.FingersCrossedConfig{TriggerLevel: golog.ERROR, Passthrough: true},
+	})
+	scoped, end := log.NewScope()
+	defer end()
+
+	for _, l := range []*golog.Log{log, scoped} {
+		buf.Reset()
+		l.Debug("one")
+		l.Error("two")
+		l.Debug("three")
+		require.Equal(`{"level":"debug","message":"one"}`+"\n"+
+			`{"level":"error","message":"two"}`+"\n"+
+			`{"level":"debug","message":"three"}`+"\n", buf.String())
+	}
+}
+
+func TestFingersCrossed_Scope(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	log := newTestLogger(t, "crossed", &buf, golog.Config{
+		Level:          golog.DEBUG,
+		FingersCrossed: golog.FingersCrossedConfig{TriggerLevel: golog.ERROR},
+	})
+
+	ctx, end := golog.StartScope(context.Background(), log)
+	golog.LoggerFromContext(ctx).Debug("discarded")
+	end()
+	golog.LoggerFromContext(ctx).Error("alone")
+
+	failed, endFailed := log.NewScope()
+	ok, endOK := log.NewScope()
+	failed.Debug("failed debug")
+	ok.Debug("ok debug")
+	failed.Error("failed")
+	endFailed()
+	endOK()
+	log.Info("unscoped")
+
+	require.Equal(`{"level":"error","message":"alone"}`+"\n"+
+		`{"level":"debug","message":"failed debug"}`+"\n"+
+		`{"level":"error","message":"failed"}`+"\n", buf.String())
+}
+
+func TestNewScope_Disabled(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := golog.NewLoggerByConfig("crossed", golog.Config{
+		Encoding:    golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(t, err)
+	scoped, end := log.NewScope()
+	scoped.Info("written")
+	end()
+	require.Equal(t, `{"level":"info","message":"written"}`+"\n", buf.String())
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	defer resetConfigs()
+	require.NotNil(t, golog.LoggerFromContext(context.Background()))
+}
@@ -5,7 +5,15 @@ import (
 	"sync/atomic"
 )
 
-type loggerProviderFactory func() Logger
+type loggerProviderFactory func() globalLoggers
+
+// globalLoggers holds the global logger in the two shapes it is used in.
+type globalLoggers struct {
+	// wrapped is called by the package-level functions and skips their frame.
+	wrapped Logger
+	// direct is the same logger for code that calls it without such a wrapper.
+	direct Logger
+}
 
 //nolint:gochecknoglobals
 var (
@@ -17,22 +25,18 @@ func init() {
 }
 
 func newLoggerProviderFactory() loggerProviderFactory {
-	return sync.OnceValue(func() Logger {
-		return New(defaultModule).CallerSkip(1)
+	return sync.OnceValue(func() globalLoggers {
+		l := New(defaultModule)
+		return globalLoggers{wrapped: l.clone().CallerSkip(1), direct: l}
 	})
 }
 
-// field is a shortcut to create Field.
-func field(k string, v any) Field {
-	return Field{Key: k, Val: v}
-}
-
-// Panicf logs a message using Panic level and panics.
+// Panicf logs a message using Panic level, then panics unless OnPanic says otherwise.
 func Panicf(format string, args ...any) {
 	loggerProvider().Panicf(format, args...)
 }
 
-// Fatalf logs a message using Fatal level and exits with status 1.
+// Fatalf logs a message using Fatal level, then exits with status 1 unless OnFatal says otherwise.
 func Fatalf(format string, args ...any) {
 	loggerProvider().Fatalf(format, args...)
 }
@@ -59,15 +63,20 @@ func Debugf(format string, args ...any) {
 
 // WithValues returns a logger configured with the key-value pairs.
 func WithValues(keysAndVals ...any) Logger {
-	return loggerProvider().WithValues(keysAndVals...)
+	return directLogger().WithValues(keysAndVals...)
+}
+
+// WithGroup returns a logger that nests the fields added after this call under the group name.
+func WithGroup(name string) FieldLogger {
+	return directLogger().WithGroup(name)
 }
 
-// Panic logs a message using Panic level and panics.
+// Panic logs a message using Panic level, then panics unless OnPanic says otherwise.
 func Panic(msg string, keysAndVals ...any) {
 	loggerProvider().Panic(msg, keysAndVals...)
 }
 
-// Fatal logs a message using Fatal level and exits with status 1.
+// Fatal logs a message using Fatal level, then exits with status 1 unless OnFatal says otherwise.
 func Fatal(msg string, keysAndVals ...any) {
 	loggerProvider().Fatal(msg, keysAndVals...)
 }
@@ -92,7 +101,19 @@ func Debug(msg string, keysAndVals ...any) {
 	loggerProvider().Debug(msg, keysAndVals...)
 }
 
+// LogFields logs a message with typed fields at the given level.
+func LogFields(level Level, msg string, fields ...Field) {
+	LogFieldsTo(loggerProvider(), level, msg, fields...)
+}
+
 func loggerProvider() Logger {
 	f := loggerProviderFactoryFn.Load().(loggerProviderFactory)
-	return f()
+	return f().wrapped
+}
+
+// directLogger returns the global logger for callers that log through it themselves,
+// so the caller is resolved at their call site rather than one frame above it.
+func directLogger() Logger {
+	f := loggerProviderFactoryFn.Load().(loggerProviderFactory)
+	return f().direct
 }
@@ -0,0 +1,63 @@
+package gologtest_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	require := require.New(t)
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := gologtest.NewClock(start)
+	var buf bytes.Buffer
+	obs := gologtest.NewObserver()
+	log, err := golog.NewLoggerByConfig("clock", golog.Config{
+		Encoding:      golog.JSONEncoding,
+		Handler:       golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+		Clock:         clock,
+		EntryHandlers: []golog.EntryHandler{obs},
+	})
+	require.NoError(err)
+
+	log.Info("one")
+	clock.Add(time.Minute)
+	log.Info("two")
+	require.Equal(`{"time":"2024-01-02T03:04:05Z","level":"info","message":"one"}`+"\n"+
+		`{"time":"2024-01-02T03:05:05Z","level":"info","message":"two"}`+"\n", buf.String())
+	require.Equal(start, obs.All()[0].Time)
+}
+
+func TestClock_RotateFile(t *testing.T) {
+	require := require.New(t)
+	clock := gologtest.NewClock(time.Date(2024, 1, 2, 23, 59, 0, 0, time.UTC))
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := golog.NewLoggerByConfig("clock", golog.Config{
+		Encoding:    golog.TextEncoding,
+		TextEncoder: golog.TextEncoderConfig{DisableColor: true, TimeFormat: time.DateOnly},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeRotateFile, RotateFile: golog.RotateFileConfig{Filename: path}},
+		Clock:       clock,
+	})
+	require.NoError(err)
+	defer log.Close()
+
+	log.Info("one")
+	clock.Add(time.Minute)
+	log.Info("two")
+
+	// Backups are named after the time of the rotation.
+	b, err := os.ReadFile(filepath.Join(filepath.Dir(path), "app-20240103.log.1704240000"))
+	require.NoError(err)
+	require.Contains(string(b), "2024-01-02")
+	require.Contains(string(b), "one")
+	b, err = os.ReadFile(path)
+	require.NoError(err)
+	require.Contains(string(b), "2024-01-03")
+	require.Contains(string(b), "two")
+}
@@ -0,0 +1,149 @@
+// Package gologtest provides helpers to test code logging with golog: an Observer
+// recording structured entries, and assertions on them.
+//
+// Routing the default config of golog to a test:
+//
+//	func TestHandler(t *testing.T) {
+//		obs := gologtest.New(t)
+//		handle()
+//		gologtest.AssertLogged(t, obs, golog.ERROR, "request failed", "status", 500)
+//	}
+package gologtest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/millken/golog"
+)
+
+// New resets the configs of golog and sets the default config so that loggers created
+// afterwards, including the global one, log at DEBUG level to t.Log and are observed by
+// the returned Observer. The configs are reset again when the test ends.
+// Tests using New must not run in parallel.
+func New(t testing.TB) *Observer {
+	t.Helper()
+	obs := NewObserver()
+	w := newTestWriter(t)
+	golog.ResetConfigs()
+	golog.SetLevel(golog.DEBUG)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableColor: true, ShowModuleName: true})
+	golog.SetWriter(w)
+	golog.SetEntryHandlers(obs)
+	t.Cleanup(func() {
+		w.stop()
+		golog.ResetConfigs()
+	})
+	return obs
+}
+
+// NewLogger returns a logger named module, logging at DEBUG level to t.Log and observed
+// by the returned Observer. The configs of golog are not changed.
+func NewLogger(t testing.TB, module string) (*golog.Log, *Observer) {
+	t.Helper()
+	obs := NewObserver()
+	w := newTestWriter(t)
+	t.Cleanup(w.stop)
+	log, err := golog.NewLoggerByConfig(module, golog.Config{
+		Level:         golog.DEBUG,
+		Encoding:      golog.TextEncoding,
+		TextEncoder:   golog.TextEncoderConfig{DisableColor: true, ShowModuleName: true},
+		Handler:       golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: w},
+		EntryHandlers: []golog.EntryHandler{obs},
+	})
+	if err != nil {
+		t.Fatalf("gologtest: %v", err)
+	}
+	return log, obs
+}
+
+// testWriter writes each entry with t.Log, until the test ends.
+type testWriter struct {
+	mu      sync.Mutex
+	t       testing.TB
+	stopped bool
+}
+
+func newTestWriter(t testing.TB) *testWriter {
+	return &testWriter{t: t}
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// Logging after the end of the test makes t.Log panic.
+	if !w.stopped {
+		w.t.Log(string(bytes.TrimRight(p, "\n")))
+	}
+	return len(p), nil
+}
+
+func (w *testWriter) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+}
+
+// AssertLogged reports a failure unless obs recorded an entry of level with the message msg
+// and the fields described by keysAndVals, given as key/value pairs with dotted keys for groups.
+func AssertLogged(t testing.TB, obs *Observer, level golog.Level, msg string, keysAndVals ...any) bool {
+	t.Helper()
+	if len(matching(obs, level, msg, keysAndVals)) > 0 {
+		return true
+	}
+	t.Errorf("gologtest: no %s entry %q%s, recorded:\n%s", level, msg, describeFields(keysAndVals), describe(obs.All()))
+	return false
+}
+
+// AssertNotLogged reports a failure if obs recorded an entry of level with the message msg
+// and the fields described by keysAndVals.
+func AssertNotLogged(t testing.TB, obs *Observer, level golog.Level, msg string, keysAndVals ...any) bool {
+	t.Helper()
+	found := matching(obs, level, msg, keysAndVals)
+	if len(found) == 0 {
+		return true
+	}
+	t.Errorf("gologtest: unexpected %s entry %q%s:\n%s", level, msg, describeFields(keysAndVals), describe(found))
+	return false
+}
+
+// AssertCount reports a failure unless obs recorded n entries of level.
+func AssertCount(t testing.TB, obs *Observer, level golog.Level, n int) bool {
+	t.Helper()
+	found := obs.All().FilterLevel(level)
+	if len(found) == n {
+		return true
+	}
+	t.Errorf("gologtest: %d %s entries recorded, want %d:\n%s", len(found), level, n, describe(found))
+	return false
+}
+
+func matching(obs *Observer, level golog.Level, msg string, keysAndVals []any) Entries {
+	found := obs.All().FilterLevel(level).FilterMessage(msg)
+	for i := 0; i+1 < len(keysAndVals); i += 2 {
+		key, _ := keysAndVals[i].(string)
+		found = found.FilterField(key, keysAndVals[i+1])
+	}
+	return found
+}
+
+func describeFields(keysAndVals []any) string {
+	if len(keysAndVals) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" with %v", keysAndVals)
+}
+
+func describe(es Entries) string {
+	if len(es) == 0 {
+		return "\t(none)"
+	}
+	var b strings.Builder
+	for _, e := range es {
+		fmt.Fprintf(&b, "\t%s %q %v\n", e.Level, e.Message, e.FieldMap())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,94 @@
+package gologtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the failures reported by the assertions.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestNew(t *testing.T) {
+	require := require.New(t)
+	obs := gologtest.New(t)
+
+	golog.Info("global", "user", "john")
+	golog.New("mod").WithGroup("req").Debug("module", golog.Int("id", 7))
+
+	entries := obs.All()
+	require.Equal([]string{"global", "module"}, entries.Messages())
+	require.Equal("mod", entries[1].Module)
+	require.Equal(map[string]any{"req": map[string]any{"id": int64(7)}}, entries[1].FieldMap())
+	require.Contains(entries[0].Caller.File, "gologtest_test.go")
+
+	gologtest.AssertLogged(t, obs, golog.INFO, "global", "user", "john")
+	gologtest.AssertLogged(t, obs, golog.DEBUG, "module", "req.id", 7)
+	gologtest.AssertNotLogged(t, obs, golog.ERROR, "global")
+	gologtest.AssertCount(t, obs, golog.DEBUG, 1)
+}
+
+func TestNew_ResetsConfigs(t *testing.T) {
+	t.Run("observed", func(t *testing.T) {
+		gologtest.New(t)
+		require.Equal(t, golog.DEBUG, golog.GetConfigs().Default.Level)
+	})
+	require.Equal(t, golog.INFO, golog.GetConfigs().Default.Level)
+	require.Empty(t, golog.GetConfigs().Default.EntryHandlers)
+}
+
+func TestNewLogger(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "test")
+	log.Warn("one", "n", 1)
+	log.Error("two", "err", fmt.Errorf("failed"))
+
+	require.Equal(2, obs.Len())
+	require.Equal(1, obs.All().FilterField("n", int64(1)).Len())
+	require.Equal(1, obs.All().FilterField("n", uint8(1)).Len())
+	require.Equal(1, obs.All().FilterFieldKey("err").Len())
+	require.Equal(1, obs.All().FilterMessageSnippet("tw").Len())
+	require.Equal(2, obs.All().FilterModule("test").Len())
+	require.Equal(2, obs.TakeAll().Len())
+	require.Zero(obs.Len())
+}
+
+func TestAssertions_Fail(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "test")
+	log.Info("done", "n", 1)
+
+	r := &recorder{TB: t}
+	require.False(gologtest.AssertLogged(r, obs, golog.INFO, "done", "n", 2))
+	require.False(gologtest.AssertNotLogged(r, obs, golog.INFO, "done"))
+	require.False(gologtest.AssertCount(r, obs, golog.INFO, 2))
+	require.Len(r.errors, 3)
+	require.Contains(r.errors[0], `info "done" map[n:1]`)
+}
+
+func TestLoggedEntry_Field(t *testing.T) {
+	require := require.New(t)
+	e := gologtest.LoggedEntry{Fields: []golog.Field{
+		golog.String("a", "1"),
+		{Key: "g", Type: golog.GroupType, Val: []golog.Field{golog.Bool("b", true)}},
+	}}
+	f, ok := e.Field("g.b")
+	require.True(ok)
+	require.Equal(true, f.Value())
+	_, ok = e.Field("a.b")
+	require.False(ok)
+	_, ok = e.Field("c")
+	require.False(ok)
+}
@@ -0,0 +1,232 @@
+package gologtest
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/millken/golog"
+)
+
+// LoggedEntry is an entry recorded by an Observer.
+type LoggedEntry struct {
+	Time    time.Time
+	Level   golog.Level
+	Module  string
+	Message string
+	// Fields holds the fields of the entry, groups holding their fields in Val.
+	Fields []golog.Field
+	// Caller is the frame of the code that logged the entry.
+	Caller runtime.Frame
+}
+
+// Field returns the field with key, groups being searched with dotted keys such as "req.id".
+func (e LoggedEntry) Field(key string) (golog.Field, bool) {
+	fields := e.Fields
+	for {
+		name, rest, nested := strings.Cut(key, ".")
+		f, ok := findField(fields, name)
+		if !ok || !nested {
+			return f, ok
+		}
+		if fields, ok = f.Val.([]golog.Field); !ok || f.Type != golog.GroupType {
+			return golog.Field{}, false
+		}
+		key = rest
+	}
+}
+
+// findField returns the last field of fields with key, as it is the one decoders keep.
+func findField(fields []golog.Field, key string) (golog.Field, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i], true
+		}
+	}
+	return golog.Field{}, false
+}
+
+// FieldMap returns the values of the fields by key, groups as nested maps.
+func (e LoggedEntry) FieldMap() map[string]any {
+	return fieldMap(e.Fields)
+}
+
+func fieldMap(fields []golog.Field) map[string]any {
+	m := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if children, ok := f.Val.([]golog.Field); ok && f.Type == golog.GroupType {
+			m[f.Key] = fieldMap(children)
+			continue
+		}
+		m[f.Key] = f.Value()
+	}
+	return m
+}
+
+// Observer is an EntryHandler recording the entries of the loggers it is set on.
+type Observer struct {
+	mu      sync.Mutex
+	entries []LoggedEntry
+}
+
+// NewObserver returns an Observer, to be set in Config.EntryHandlers.
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Enabled implements golog.EntryHandler, taking the entries of all levels.
+func (o *Observer) Enabled(golog.Level) bool {
+	return true
+}
+
+// NeedsData implements golog.DataHandler: the observer records the fields, not the encoded entry.
+func (o *Observer) NeedsData() bool {
+	return false
+}
+
+// Handle implements golog.EntryHandler.
+func (o *Observer) Handle(e *golog.Entry) error {
+	entry := LoggedEntry{
+		Time:    e.Time,
+		Level:   e.Level,
+		Module:  e.Module,
+		Message: e.Message,
+		Fields:  e.NestedFields(),
+		Caller:  e.CallerFrame(),
+	}
+	o.mu.Lock()
+	o.entries = append(o.entries, entry)
+	o.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of recorded entries.
+func (o *Observer) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// All returns a copy of the recorded entries.
+func (o *Observer) All() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append(Entries(nil), o.entries...)
+}
+
+// TakeAll returns the recorded entries and forgets them.
+func (o *Observer) TakeAll() Entries {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Entries is a list of recorded entries.
+type Entries []LoggedEntry
+
+// Len returns the number of entries.
+func (es Entries) Len() int {
+	return len(es)
+}
+
+// Messages returns the messages of the entries.
+func (es Entries) Messages() []string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Message
+	}
+	return msgs
+}
+
+// Filter returns the entries for which keep returns true.
+func (es Entries) Filter(keep func(LoggedEntry) bool) Entries {
+	var filtered Entries
+	for _, e := range es {
+		if keep(e) {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel returns the entries of level.
+func (es Entries) FilterLevel(level golog.Level) Entries {
+	return es.Filter(func(e LoggedEntry) bool { return e.Level == level })
+}
+
+// FilterModule returns the entries of the logger named module.
+func (es Entries) FilterModule(module string) Entries {
+	return es.Filter(func(e LoggedEntry) bool { return e.Module == module })
+}
+
+// FilterMessage returns the entries with the message msg.
+func (es Entries) FilterMessage(msg string) Entries {
+	return es.Filter(func(e LoggedEntry) bool { return e.Message == msg })
+}
+
+// FilterMessageSnippet returns the entries whose message contains snippet.
+func (es Entries) FilterMessageSnippet(snippet string) Entries {
+	return es.Filter(func(e LoggedEntry) bool { return strings.Contains(e.Message, snippet) })
+}
+
+// FilterFieldKey returns the entries with a field of key, dotted for groups.
+func (es Entries) FilterFieldKey(key string) Entries {
+	return es.Filter(func(e LoggedEntry) bool {
+		_, ok := e.Field(key)
+		return ok
+	})
+}
+
+// FilterField returns the entries with a field of key, dotted for groups, holding value.
+// Integers and floats match regardless of their type, so that Int("n", 1) matches 1.
+func (es Entries) FilterField(key string, value any) Entries {
+	return es.Filter(func(e LoggedEntry) bool {
+		f, ok := e.Field(key)
+		return ok && valueEqual(f.Value(), value)
+	})
+}
+
+// valueEqual compares a and b, converting numbers.
+func valueEqual(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case isInt(va) && isInt(vb):
+		return va.Int() == vb.Int()
+	case isUint(va) && isUint(vb):
+		return va.Uint() == vb.Uint()
+	case isInt(va) && isUint(vb):
+		return va.Int() >= 0 && uint64(va.Int()) == vb.Uint()
+	case isUint(va) && isInt(vb):
+		return vb.Int() >= 0 && va.Uint() == uint64(vb.Int())
+	case isFloat(va) && isFloat(vb):
+		return va.Float() == vb.Float()
+	}
+	return false
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
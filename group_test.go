@@ -0,0 +1,74 @@
+package golog_test
+
+import (
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_JSON(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	golog.Info("request", golog.Group("http", "method", "GET", "status", 200), "took", 3)
+	require.True(validJSON(buf.String()))
+	require.Equal(`{"level":"info","message":"request","http":{"method":"GET","status":200},"took":3}`+"\n", buf.String())
+
+	buf.Reset()
+	l := golog.WithValues("app", "api").WithGroup("req").WithValues("id", 7).WithGroup("http")
+	l.Info("nested", "method", "POST", golog.Group("headers", "accept", "json"))
+	require.True(validJSON(buf.String()))
+	require.Equal(`{"level":"info","message":"nested","app":"api","req":{"id":7,"http":{"method":"POST","headers":{"accept":"json"}}}}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.WithGroup("empty").WithGroup("").Info("omitted", golog.Group("none"), golog.Group("outer", golog.Group("inner")))
+	require.Equal(`{"level":"info","message":"omitted"}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.WithValues("app", "api").WithGroup("req").LogFields(golog.INFO, "typed", golog.Int("id", 7))
+	require.Equal(`{"level":"info","message":"typed","app":"api","req":{"id":7}}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.Info("lazy", "g", golog.Lazy(func() any { return golog.Group("", "a", 1) }))
+	require.Equal(`{"level":"info","message":"lazy","g":{"a":1}}`+"\n", buf.String())
+}
+
+func TestGroup_Text(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+
+	l := golog.WithGroup("req").WithValues("id", 7)
+	l.Info("nested", golog.Group("http", "method", "GET", golog.Group("empty")), "user", benchUser.Address)
+	require.Equal("INFO nested req.id=7 req.http.method=GET req.user.city=Paris req.user.zip=75001\n", buf.String())
+
+	buf.Reset()
+	golog.LogFieldsTo(l, golog.WARNING, "typed", golog.Group("a", golog.Int("b", 1)))
+	require.Equal("WARN typed req.id=7 req.a.b=1\n", buf.String())
+}
+
+func TestGroup_NoAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted under the race detector")
+	}
+	log, err := golog.NewLoggerByConfig("alloc", golog.Config{
+		Handler: golog.HandlerConfig{
+			Type: golog.HandlerTypeFile,
+			File: golog.FileConfig{Path: ""},
+		},
+	})
+	require.NoError(t, err)
+	l := log.WithGroup("http").(*golog.Log)
+	allocs := testing.AllocsPerRun(100, func() {
+		l.LogFields(golog.INFO, "request", golog.String("method", "GET"), golog.Int("status", 200))
+	})
+	require.Zero(t, allocs)
+}
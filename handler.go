@@ -0,0 +1,70 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/millken/golog/internal/stack"
+)
+
+// EntryHandler receives the entries of a logger, in addition to its writer.
+type EntryHandler interface {
+	// Enabled reports whether the handler takes the entries of level. It is called when
+	// the logger is created, so that levels the logger filters out of its writer are
+	// still logged when a handler takes them, and for each entry.
+	Enabled(level Level) bool
+	// Handle is called with each entry once encoded and written. The entry is
+	// reused afterwards: it and its fields must not be retained.
+	Handle(e *Entry) error
+}
+
+// DataHandler is an EntryHandler telling whether it reads Entry.Data, the entry as
+// encoded by the logger. Handlers not implementing it are assumed to read it. Entries
+// the logger does not write are only encoded for the handlers reading Data.
+type DataHandler interface {
+	EntryHandler
+	NeedsData() bool
+}
+
+// handlersEnabled reports whether a handler of the logger takes the entries of level.
+func (l *Log) handlersEnabled(level Level) bool {
+	for _, h := range l.handlers {
+		if h.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlersNeedData reports whether a handler of the logger taking the entries of level reads Entry.Data.
+func (l *Log) handlersNeedData(level Level) bool {
+	for _, h := range l.handlers {
+		if !h.Enabled(level) {
+			continue
+		}
+		if d, ok := h.(DataHandler); !ok || d.NeedsData() {
+			return true
+		}
+	}
+	return false
+}
+
+// handle passes e to the handlers of the logger taking its level.
+func (l *Log) handle(e *Entry) {
+	resolved := false
+	for _, h := range l.handlers {
+		if !h.Enabled(e.Level) {
+			continue
+		}
+		if !resolved {
+			// Same depth as Encoder.Encode, called from output as well.
+			if frames := stack.Tracer(int(DefaultCallerSkip.Load())+e.CallerSkip(), false, 0); len(frames) > 0 {
+				e.frame = frames[0]
+			}
+			resolved = true
+		}
+		if err := h.Handle(e); err != nil {
+			fmt.Fprintf(os.Stderr, "golog: failed to handle log: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package golog_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+type levelHandler struct {
+	level   golog.Level
+	entries []string
+	fields  [][]golog.Field
+	callers []string
+}
+
+func (h *levelHandler) Enabled(level golog.Level) bool {
+	return level <= h.level
+}
+
+func (h *levelHandler) Handle(e *golog.Entry) error {
+	h.entries = append(h.entries, string(e.Bytes()))
+	h.fields = append(h.fields, e.NestedFields())
+	h.callers = append(h.callers, e.CallerFrame().Function)
+	return nil
+}
+
+func TestEntryHandlers(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	warn := &levelHandler{level: golog.WARNING}
+	all := &levelHandler{level: golog.DEBUG}
+	log, err := golog.NewLoggerByConfig("handler", golog.Config{
+		Level:         golog.DEBUG,
+		Encoding:      golog.JSONEncoding,
+		JSONEncoder:   golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:       golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+		EntryHandlers: []golog.EntryHandler{warn, all},
+	})
+	require.NoError(err)
+
+	log.Debug("one")
+	log.WithGroup("g").Warn("two", "a", 1)
+
+	require.Equal([]string{`{"level":"warning","message":"two","g":{"a":1}}` + "\n"}, warn.entries)
+	require.Len(all.entries, 2)
+	require.Equal([]golog.Field{{Key: "g", Type: golog.GroupType, Val: []golog.Field{golog.Any("a", 1)}}}, warn.fields[0])
+	require.Equal("github.com/millken/golog_test.TestEntryHandlers", all.callers[0])
+}
+
+type fieldsHandler struct {
+	levelHandler
+}
+
+func (*fieldsHandler) NeedsData() bool {
+	return false
+}
+
+func TestEntryHandlers_NoData(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	fields := &fieldsHandler{levelHandler{level: golog.DEBUG}}
+	log := newTestLogger(t, "handler", &buf, golog.Config{EntryHandlers: []golog.EntryHandler{fields}})
+
+	log.Debug("unwritten", "a", 1)
+	log.Info("written")
+	require.Equal([]string{"", `{"level":"info","message":"written"}` + "\n"}, fields.entries)
+	require.Equal([]golog.Field{golog.Any("a", 1)}, fields.fields[0])
+	require.Equal("github.com/millken/golog_test.TestEntryHandlers_NoData", fields.callers[0])
+	require.Equal(`{"level":"info","message":"written"}`+"\n", buf.String())
+}
+
+func TestField_Value(t *testing.T) {
+	require := require.New(t)
+	now := time.Now()
+	err := errors.New("failed")
+	for _, tc := range []struct {
+		field golog.Field
+		want  any
+	}{
+		{golog.String("k", "v"), "v"},
+		{golog.Int("k", -1), int64(-1)},
+		{golog.Uint64("k", 1), uint64(1)},
+		{golog.Float64("k", 1.5), 1.5},
+		{golog.Bool("k", true), true},
+		{golog.Duration("k", time.Second), time.Second},
+		{golog.Err(err), err},
+		{golog.Any("k", []int{1}), []int{1}},
+	} {
+		require.Equal(tc.want, tc.field.Value())
+	}
+	require.True(now.Equal(golog.Time("k", now).Value().(time.Time)))
+}
@@ -0,0 +1,83 @@
+package golog_test
+
+import (
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func logFailure(log *golog.Log, msg string) {
+	golog.Helper()
+	log.Error(msg)
+}
+
+func logNestedFailure(log *golog.Log, msg string) {
+	golog.Helper()
+	logFailure(log, msg)
+}
+
+func logGlobalFailure(msg string) {
+	golog.Helper()
+	golog.Error(msg)
+}
+
+func TestHelper(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("helper", golog.Config{
+		TextEncoder: golog.TextEncoderConfig{
+			DisableTimestamp: true,
+			DisableColor:     true,
+			CallerFunction:   true,
+			CallerFormat:     golog.CallerFormatRelative,
+		},
+		CallerLevels: []golog.Level{golog.ERROR},
+		Handler:      golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(err)
+
+	logFailure(log, "direct")
+	require.Regexp(`^ERRO helper_test\.go:\d+ \(github\.com/millken/golog_test\.TestHelper\) direct\n$`, buf.String())
+
+	buf.Reset()
+	logNestedFailure(log, "nested")
+	require.Regexp(`^ERRO helper_test\.go:\d+ \(github\.com/millken/golog_test\.TestHelper\) nested\n$`, buf.String())
+}
+
+func TestHelper_Global(t *testing.T) {
+	defer resetConfigs()
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{
+		DisableTimestamp: true,
+		DisableColor:     true,
+		CallerFunction:   true,
+		CallerFormat:     golog.CallerFormatRelative,
+	})
+	golog.SetCallerLevels(golog.ERROR)
+
+	logGlobalFailure("global")
+	require.Regexp(t, `^ERRO helper_test\.go:\d+ \(github\.com/millken/golog_test\.TestHelper_Global\) global\n$`, buf.String())
+}
+
+func TestHelper_StackDepth(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("helper", golog.Config{
+		TextEncoder: golog.TextEncoderConfig{
+			DisableTimestamp: true,
+			DisableColor:     true,
+			CallerFormat:     golog.CallerFormatRelative,
+			StackDepth:       1,
+		},
+		CallerLevels:     []golog.Level{golog.ERROR},
+		StacktraceLevels: []golog.Level{golog.ERROR},
+		Handler:          golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(err)
+
+	logNestedFailure(log, "shallow")
+	require.Regexp(`^ERRO helper_test\.go:\d+  shallow\ngithub\.com/millken/golog_test\.TestHelper_StackDepth\n\t\S+/helper_test\.go:\d+\n$`, buf.String())
+}
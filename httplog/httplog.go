@@ -0,0 +1,277 @@
+// Package httplog provides net/http middleware logging one entry per request with golog.
+//
+//	mux := http.NewServeMux()
+//	http.ListenAndServe(":8080", httplog.Middleware(httplog.Config{Recover: true})(mux))
+//
+// Handlers retrieve the logger of the request, carrying its request and trace ids, with
+// golog.LoggerFromContext(r.Context()).
+package httplog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/millken/golog"
+)
+
+// Field names of the entries.
+const (
+	MethodFieldName     = "method"
+	PathFieldName       = "path"
+	StatusFieldName     = "status"
+	BytesFieldName      = "bytes"
+	DurationFieldName   = "duration"
+	RemoteAddrFieldName = "remoteAddr"
+	UserAgentFieldName  = "userAgent"
+	RequestIDFieldName  = "requestId"
+	TraceIDFieldName    = "traceId"
+	SpanIDFieldName     = "spanId"
+	PanicFieldName      = "panic"
+	StackFieldName      = "stack"
+)
+
+// DefaultRequestIDHeader is the header carrying the request id if Config.RequestIDHeader is not set.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the length above which incoming request ids are replaced.
+const maxRequestIDLength = 128
+
+// Format defines how requests are logged.
+type Format string
+
+const (
+	// FormatStructured logs requests as entries with one field per property. This is the default.
+	FormatStructured Format = "structured"
+	// FormatCombined logs requests as entries whose message is in the Apache combined log format.
+	FormatCombined Format = "combined"
+)
+
+// Config is the configuration of the middleware.
+type Config struct {
+	// Logger logs the requests. The logger carried by the context of the request, or
+	// the global logger, is used if not set.
+	Logger golog.Logger
+	// Message is the message of the entries, "request" if not set.
+	Message string
+	// Format is how requests are logged: structured (default) or combined.
+	Format Format
+	// Level returns the level of the entry of a response status. 5xx statuses are logged
+	// at ERROR level, 4xx at WARNING and others at INFO if not set.
+	Level func(status int) golog.Level
+	// RequestIDHeader is the header carrying the request id, X-Request-ID if not set.
+	// Incoming ids are kept, otherwise one is generated, and the id is set on the response.
+	RequestIDHeader string
+	// GenerateRequestID generates the request ids, 16 random bytes in hexadecimal if not set.
+	GenerateRequestID func() string
+	// Recover recovers the panics of the handler, logs them at ERROR level with their
+	// stack trace and responds with 500 if nothing was written yet.
+	Recover bool
+	// Clock tells the time of the requests, golog.SystemClock if not set.
+	Clock golog.Clock
+}
+
+type requestIDKey struct{}
+
+// RequestID returns the id of the request of ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Middleware returns the middleware logging the requests as configured by cfg.
+func Middleware(cfg Config) func(http.Handler) http.Handler {
+	if cfg.Message == "" {
+		cfg.Message = "request"
+	}
+	if cfg.Level == nil {
+		cfg.Level = statusLevel
+	}
+	if cfg.RequestIDHeader == "" {
+		cfg.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if cfg.GenerateRequestID == nil {
+		cfg.GenerateRequestID = generateRequestID
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = golog.SystemClock
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg.serve(next, w, r)
+		})
+	}
+}
+
+func (cfg *Config) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	start := cfg.Clock.Now()
+	logger := cfg.Logger
+	if logger == nil {
+		logger = golog.LoggerFromContext(r.Context())
+	}
+
+	id := r.Header.Get(cfg.RequestIDHeader)
+	if !validRequestID(id) {
+		id = cfg.GenerateRequestID()
+		r.Header.Set(cfg.RequestIDHeader, id)
+	}
+	w.Header().Set(cfg.RequestIDHeader, id)
+	scope := []any{golog.String(RequestIDFieldName, id)}
+	if traceID, spanID, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+		scope = append(scope, golog.String(TraceIDFieldName, traceID), golog.String(SpanIDFieldName, spanID))
+	}
+	logger = logger.WithValues(scope...)
+	ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+	r = r.WithContext(golog.ContextWithLogger(ctx, logger))
+
+	rw := &responseWriter{ResponseWriter: w}
+	panicked := true
+	defer func() {
+		if !cfg.Recover {
+			cfg.log(logger, r, rw, start, panicked)
+			return
+		}
+		v := recover()
+		if v == nil {
+			cfg.log(logger, r, rw, start, false)
+			return
+		}
+		if v == http.ErrAbortHandler {
+			panic(v)
+		}
+		golog.LogFieldsTo(logger, golog.ERROR, "panic recovered",
+			golog.String(MethodFieldName, r.Method),
+			golog.String(PathFieldName, r.URL.Path),
+			golog.Any(PanicFieldName, v),
+			golog.String(StackFieldName, string(debug.Stack())),
+		)
+		if rw.status == 0 {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		cfg.log(logger, r, rw, start, false)
+	}()
+	next.ServeHTTP(rw, r)
+	panicked = false
+}
+
+// log logs the entry of the request. The request of a handler that panicked without
+// writing a status is logged with status 500, as the server aborts it.
+func (cfg *Config) log(logger golog.Logger, r *http.Request, rw *responseWriter, start time.Time, panicked bool) {
+	status := rw.statusCode()
+	if panicked && rw.status == 0 {
+		status = http.StatusInternalServerError
+	}
+	level := cfg.Level(status)
+	if cfg.Format == FormatCombined {
+		golog.LogFieldsTo(logger, level, combinedLine(r, status, rw.bytes, start))
+		return
+	}
+	golog.LogFieldsTo(logger, level, cfg.Message,
+		golog.String(MethodFieldName, r.Method),
+		golog.String(PathFieldName, r.URL.Path),
+		golog.Int(StatusFieldName, status),
+		golog.Int64(BytesFieldName, rw.bytes),
+		golog.Duration(DurationFieldName, cfg.Clock.Now().Sub(start)),
+		golog.String(RemoteAddrFieldName, r.RemoteAddr),
+		golog.String(UserAgentFieldName, r.UserAgent()),
+	)
+}
+
+func statusLevel(status int) golog.Level {
+	switch {
+	case status >= 500:
+		return golog.ERROR
+	case status >= 400:
+		return golog.WARNING
+	}
+	return golog.INFO
+}
+
+func generateRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether an incoming request id can be kept: it is not empty,
+// not too long and made of printable ASCII characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTraceparent returns the trace and parent ids of a W3C traceparent header,
+// such as 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func parseTraceparent(h string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(h), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	// Version 00 has exactly four parts, later ones may add some.
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	traceID, spanID = parts[1], parts[2]
+	if !isLowerHex(parts[0]) || !isLowerHex(traceID) || !isLowerHex(spanID) || !isLowerHex(parts[3]) ||
+		len(traceID) != 32 || len(spanID) != 16 || len(parts[3]) != 2 ||
+		strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// combinedLine formats the request in the Apache combined log format, where time is the
+// time the request was received:
+//
+//	host ident user [time] "request line" status bytes "referer" "user agent"
+func combinedLine(r *http.Request, status int, size int64, start time.Time) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	}
+	bytes := "-"
+	if size > 0 {
+		bytes = strconv.FormatInt(size, 10)
+	}
+	return fmt.Sprintf("%s - %s [%s] %q %d %s %q %q",
+		orDash(host), user, start.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method+" "+r.URL.RequestURI()+" "+r.Proto, status, bytes,
+		orDash(r.Referer()), orDash(r.UserAgent()))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
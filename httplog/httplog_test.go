@@ -0,0 +1,190 @@
+package httplog_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/millken/golog/httplog"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedClock(t *testing.T) *gologtest.Clock {
+	t.Helper()
+	return gologtest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+}
+
+func TestMiddleware(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "http")
+	clock := fixedClock(t)
+	var handlerID string
+	h := httplog.Middleware(httplog.Config{Logger: log, Clock: clock})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlerID = httplog.RequestID(r.Context())
+		golog.LoggerFromContext(r.Context()).Info("handling")
+		clock.Add(time.Second)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1?full=1", nil)
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	id := rec.Header().Get("X-Request-ID")
+	require.Len(id, 32)
+	require.Equal(id, handlerID)
+	entries := obs.All()
+	require.Len(entries, 2)
+	require.Equal(map[string]any{
+		"requestId": id,
+		"traceId":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":    "00f067aa0ba902b7",
+	}, entries[0].FieldMap())
+	require.Equal(golog.WARNING, entries[1].Level)
+	require.Equal("request", entries[1].Message)
+	require.Equal(map[string]any{
+		"requestId":  id,
+		"traceId":    "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":     "00f067aa0ba902b7",
+		"method":     "GET",
+		"path":       "/items/1",
+		"status":     int64(404),
+		"bytes":      int64(7),
+		"duration":   time.Second,
+		"remoteAddr": "192.0.2.1:1234",
+		"userAgent":  "test",
+	}, entries[1].FieldMap())
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "http")
+	h := httplog.Middleware(httplog.Config{
+		Logger:            log,
+		RequestIDHeader:   "X-Trace",
+		GenerateRequestID: func() string { return "generated" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, tc := range []struct{ in, want string }{
+		{"abc-123", "abc-123"},
+		{"", "generated"},
+		{"has space", "generated"},
+		{strings.Repeat("a", 129), "generated"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Trace", tc.in)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(tc.want, rec.Header().Get("X-Trace"))
+		require.Equal(tc.want, obs.TakeAll()[0].FieldMap()["requestId"])
+	}
+}
+
+func TestMiddleware_Traceparent(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "http")
+	h := httplog.Middleware(httplog.Config{Logger: log})(http.NotFoundHandler())
+	for _, tp := range []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("traceparent", tp)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		_, ok := obs.TakeAll()[0].Field("traceId")
+		require.False(t, ok, tp)
+	}
+}
+
+func TestMiddleware_Recover(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "http")
+	h := httplog.Middleware(httplog.Config{Logger: log, Recover: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/fail", nil))
+
+	require.Equal(http.StatusInternalServerError, rec.Code)
+	entries := obs.All()
+	require.Len(entries, 2)
+	require.Equal(golog.ERROR, entries[0].Level)
+	require.Equal("panic recovered", entries[0].Message)
+	require.Equal("boom", entries[0].FieldMap()["panic"])
+	require.Contains(entries[0].FieldMap()["stack"], "httplog_test.TestMiddleware_Recover")
+	require.Equal(golog.ERROR, entries[1].Level)
+	require.Equal(int64(500), entries[1].FieldMap()["status"])
+
+	abort := httplog.Middleware(httplog.Config{Logger: log, Recover: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	require.PanicsWithValue(http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestMiddleware_PanicWithoutRecover(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "http")
+	h := httplog.Middleware(httplog.Config{Logger: log})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	require.PanicsWithValue("boom", func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	})
+	entries := obs.All()
+	require.Len(entries, 1)
+	require.Equal(golog.ERROR, entries[0].Level)
+	require.Equal(int64(500), entries[0].FieldMap()["status"])
+}
+
+func TestMiddleware_Combined(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "http")
+	clock := fixedClock(t)
+	h := httplog.Middleware(httplog.Config{Logger: log, Format: httplog.FormatCombined, Clock: clock})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clock.Add(time.Minute)
+			_, _ = w.Write([]byte("hello"))
+		}))
+	req := httptest.NewRequest(http.MethodGet, "/a?b=c", nil)
+	req.SetBasicAuth("frank", "secret")
+	req.Header.Set("Referer", "http://example.com/")
+	req.Header.Set("User-Agent", "curl/8.0")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, `192.0.2.1 - frank [02/Jan/2024:03:04:05 +0000] "GET /a?b=c HTTP/1.1" 200 5 "http://example.com/" "curl/8.0"`,
+		obs.All()[0].Message)
+}
+
+func TestMiddleware_ContextLogger(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "ctx")
+	h := httplog.Middleware(httplog.Config{})(http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(golog.ContextWithLogger(context.Background(), log))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	gologtest.AssertLogged(t, obs, golog.WARNING, "request", "status", 404)
+}
+
+func TestMiddleware_Flusher(t *testing.T) {
+	log, _ := gologtest.NewLogger(t, "http")
+	h := httplog.Middleware(httplog.Config{Logger: log})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		require.True(t, ok)
+		require.NoError(t, http.NewResponseController(w).Flush())
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.True(t, rec.Flushed)
+}
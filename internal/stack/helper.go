@@ -0,0 +1,74 @@
+package stack
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	helpersMu sync.RWMutex
+	// helperFuncs holds the names of the functions marked as helpers.
+	helperFuncs = map[string]struct{}{}
+	// helperCalls holds the PCs of the MarkHelper calls already resolved.
+	helperCalls = map[uintptr]struct{}{}
+	// helperPCs caches whether the frames at the PCs returned by runtime.Callers are helpers,
+	// so that known PCs are not symbolized again. It is reset when a helper is added.
+	helperPCs  = map[uintptr]bool{}
+	hasHelpers atomic.Bool
+)
+
+// MarkHelper marks the function skip frames above the caller of MarkHelper as a helper.
+// Tracer skips helpers when looking for the caller.
+func MarkHelper(skip int) {
+	var pc [1]uintptr
+	//+2 to skip MarkHelper and runtime.Callers.
+	if runtime.Callers(skip+2, pc[:]) == 0 {
+		return
+	}
+	helpersMu.RLock()
+	_, ok := helperCalls[pc[0]]
+	helpersMu.RUnlock()
+	if ok {
+		return
+	}
+	frame, _ := runtime.CallersFrames(pc[:]).Next()
+	helpersMu.Lock()
+	helperCalls[pc[0]] = struct{}{}
+	if _, ok := helperFuncs[frame.Function]; !ok {
+		helperFuncs[frame.Function] = struct{}{}
+		clear(helperPCs)
+	}
+	helpersMu.Unlock()
+	hasHelpers.Store(true)
+}
+
+// helperCount returns the number of helpers at the start of pcs, as returned by runtime.Callers.
+func helperCount(pcs []uintptr) int {
+	if !hasHelpers.Load() {
+		return 0
+	}
+	for i, pc := range pcs {
+		if !isHelperPC(pc) {
+			return i
+		}
+	}
+	return len(pcs)
+}
+
+// isHelperPC reports whether the frame at pc, as returned by runtime.Callers, is a helper.
+func isHelperPC(pc uintptr) bool {
+	helpersMu.RLock()
+	helper, ok := helperPCs[pc]
+	helpersMu.RUnlock()
+	if ok {
+		return helper
+	}
+	// runtime.Callers returns a PC per frame, inlined ones included: the first frame of pc is its own.
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	helpersMu.Lock()
+	_, helper = helperFuncs[frame.Function]
+	helperPCs[pc] = helper
+	helpersMu.Unlock()
+	return helper
+}
@@ -0,0 +1,57 @@
+package stack
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func helperTrace(stacktrace bool) []runtime.Frame {
+	MarkHelper(0)
+	return nestedHelperTrace(stacktrace, 0)
+}
+
+func nestedHelperTrace(stacktrace bool, depth int) []runtime.Frame {
+	MarkHelper(0)
+	return Tracer(0, stacktrace, depth)
+}
+
+func TestMarkHelper(t *testing.T) {
+	require := require.New(t)
+	frames := helperTrace(false)
+	require.Len(frames, 1)
+	require.Equal("github.com/millken/golog/internal/stack.TestMarkHelper", frames[0].Function)
+
+	frames = helperTrace(true)
+	require.Greater(len(frames), 1)
+	require.Equal("github.com/millken/golog/internal/stack.TestMarkHelper", frames[0].Function)
+}
+
+func TestMarkHelper_Depth(t *testing.T) {
+	require := require.New(t)
+	frames := nestedHelperTrace(true, 1)
+	require.Len(frames, 1)
+	require.Equal("github.com/millken/golog/internal/stack.TestMarkHelper_Depth", frames[0].Function)
+
+	frames = nestedHelperTrace(false, 1)
+	require.Len(frames, 1)
+	require.Equal("github.com/millken/golog/internal/stack.TestMarkHelper_Depth", frames[0].Function)
+}
+
+func lateHelperTrace(mark bool) []runtime.Frame {
+	if mark {
+		MarkHelper(0)
+	}
+	return Tracer(0, false, 0)
+}
+
+func TestMarkHelper_AfterTrace(t *testing.T) {
+	require := require.New(t)
+	helperTrace(false)
+	frames := lateHelperTrace(false)
+	require.Equal("github.com/millken/golog/internal/stack.lateHelperTrace", frames[0].Function)
+
+	frames = lateHelperTrace(true)
+	require.Equal("github.com/millken/golog/internal/stack.TestMarkHelper_AfterTrace", frames[0].Function)
+}
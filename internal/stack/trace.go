@@ -6,34 +6,63 @@ import (
 	"github.com/millken/golog/internal/buffer"
 )
 
+// DefaultDepth is the maximum number of frames returned by Tracer when no depth is given.
+const DefaultDepth = 20
+
 // Tracer returns a slice of Frames, calling runtime.Callers.
-func Tracer(skip int, stacktrace bool) []runtime.Frame {
-	const depth = 20
+// Without stacktrace, only the frame of the caller is returned; otherwise up to depth
+// frames are, or DefaultDepth if depth is not positive. Helpers called before reaching
+// the caller are skipped and do not count in depth.
+func Tracer(skip int, stacktrace bool, depth int) []runtime.Frame {
 	var stack []runtime.Frame
 	if !stacktrace {
-		pc, file, line, ok := runtime.Caller(skip + 1)
-		if !ok {
-			return nil
+		// A single PC is enough without helpers; with them, a few are read at once.
+		var fpcs [4]uintptr
+		pcs := fpcs[:1]
+		if hasHelpers.Load() {
+			pcs = fpcs[:]
+		}
+		for {
+			//+2 to skip Tracer and runtime.Callers.
+			n := runtime.Callers(skip+2, pcs)
+			if n == 0 {
+				return nil
+			}
+			if i := helperCount(pcs[:n]); i < n {
+				pc := [1]uintptr{pcs[i]}
+				frame, _ := runtime.CallersFrames(pc[:]).Next()
+				stack = append(stack, frame)
+				return stack
+			}
+			skip += n
 		}
-		stack = append(stack, runtime.Frame{
-			PC:   pc,
-			File: file,
-			Line: line,
-		})
-		return stack
 	}
+	skip += helperFrames(skip)
 
 	//the maximum number of callers to include in the stack.
-	var fpcs [depth]uintptr
+	var fpcs [DefaultDepth]uintptr
+	pcs := fpcs[:]
+	if depth > DefaultDepth {
+		pcs = make([]uintptr, depth)
+	} else if depth > 0 {
+		pcs = fpcs[:depth]
+	}
 
 	//+2 to skip Tracer and runtime.Callers.
-	n := runtime.Callers(skip+2, fpcs[:])
+	n := runtime.Callers(skip+2, pcs)
 	if n == 0 {
 		return nil
 	}
-	frames := runtime.CallersFrames(fpcs[:n])
-	for f, more := frames.Next(); more; f, more = frames.Next() {
-		stack = append(stack, f)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		// runtime.goexit ends every goroutine stack and carries no information.
+		if f.Function != "runtime.goexit" {
+			stack = append(stack, f)
+		}
+		if !more {
+			break
+		}
 	}
 	if len(stack) == 0 {
 		return nil
@@ -41,6 +70,41 @@ func Tracer(skip int, stacktrace bool) []runtime.Frame {
 	return stack
 }
 
+// helperFrames returns the number of helper frames found from the caller of Tracer skipping
+// skip frames, before the first frame that is not a helper. The stack is walked without
+// depth limit, whatever the depth of the trace.
+func helperFrames(skip int) int {
+	if !hasHelpers.Load() {
+		return 0
+	}
+	var pcs [4]uintptr
+	n := 0
+	for {
+		//+3 to skip helperFrames, Tracer and runtime.Callers.
+		count := runtime.Callers(skip+n+3, pcs[:])
+		if count == 0 {
+			return n
+		}
+		i := helperCount(pcs[:count])
+		n += i
+		if i < count {
+			return n
+		}
+	}
+}
+
+// Filter removes in place the frames for which drop returns true.
+func Filter(frames []runtime.Frame, drop func(runtime.Frame) bool) []runtime.Frame {
+	n := 0
+	for _, f := range frames {
+		if !drop(f) {
+			frames[n] = f
+			n++
+		}
+	}
+	return frames[:n]
+}
+
 // stackFormatter formats a stack trace into a readable string representation.
 type stackFormatter struct {
 	b        *buffer.Buffer
@@ -1,6 +1,8 @@
 package stack
 
 import (
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/millken/golog/internal/buffer"
@@ -9,7 +11,7 @@ import (
 
 func TestTrace(t *testing.T) {
 	require := require.New(t)
-	frames := Tracer(0, true)
+	frames := Tracer(0, true, 0)
 	if len(frames) == 0 {
 		t.Fatal("no frames")
 	}
@@ -23,3 +25,17 @@ func TestTrace(t *testing.T) {
 	sf.FormatFrames(frames)
 	require.Contains(b.String(), "stack/trace_test.go")
 }
+
+func TestTracer_Depth(t *testing.T) {
+	require := require.New(t)
+	require.Len(Tracer(0, true, 1), 1)
+	require.Len(Tracer(0, false, 0), 1)
+	require.Equal("github.com/millken/golog/internal/stack.TestTracer_Depth", Tracer(0, false, 0)[0].Function)
+
+	frames := Tracer(0, true, 0)
+	frames = Filter(frames, func(f runtime.Frame) bool {
+		return strings.HasPrefix(f.Function, "testing.")
+	})
+	require.Len(frames, 1)
+	require.Contains(frames[0].Function, "TestTracer_Depth")
+}
@@ -3,7 +3,7 @@ package golog
 import (
 	"errors"
 	"runtime"
-	"strconv"
+	"sync"
 	"time"
 
 	"github.com/millken/golog/internal/buffer"
@@ -16,7 +16,8 @@ var (
 
 // JSONEncoder encodes entries as JSON.
 type JSONEncoder struct {
-	cfg JSONEncoderConfig
+	cfg   JSONEncoderConfig
+	types *TypeEncoders
 }
 
 // NewJSONEncoder returns a new JSONEncoder.
@@ -34,7 +35,7 @@ func (o *JSONEncoder) Encode(e *Entry) ([]byte, error) {
 	e.Data = enc.AppendBeginMarker(e.Data)
 	if !o.cfg.DisableTimestamp {
 		e.Data = enc.AppendKey(e.Data, TimestampFieldName)
-		e.Data = enc.AppendTime(e.Data, time.Now(), TimeFieldFormat)
+		e.Data = enc.AppendTime(e.Data, entryTime(e), TimeFieldFormat)
 	}
 	e.Data = enc.AppendKey(e.Data, LevelFieldName)
 	e.Data = enc.AppendString(e.Data, e.Level.String())
@@ -48,29 +49,336 @@ func (o *JSONEncoder) Encode(e *Entry) ([]byte, error) {
 	var frames []runtime.Frame
 	if e.HasFlag(FlagCaller) || e.HasFlag(FlagStacktrace) {
 		stackSkip := int(DefaultCallerSkip.Load()) + e.CallerSkip() + o.cfg.CallerSkipFrame
-		frames = stack.Tracer(stackSkip, e.HasFlag(FlagStacktrace))
+		frames = stack.Tracer(stackSkip, e.HasFlag(FlagStacktrace), o.cfg.StackDepth)
 	}
 
 	if len(frames) > 0 {
 		if e.HasFlag(FlagCaller) {
-			frame := frames[0]
-			e.Data = enc.AppendKey(e.Data, CallerFieldName)
-			e.Data = enc.AppendString(e.Data, frame.File+":"+strconv.Itoa(frame.Line))
+			e.Data = o.appendCaller(e.Data, frames[0])
 		}
 		if e.HasFlag(FlagStacktrace) {
-			buffer := buffer.Get()
-			stackfmt := stack.NewStackFormatter(buffer)
-			stackfmt.FormatFrames(frames)
+			if o.cfg.FilterStack {
+				frames = stack.Filter(frames, isInternalFrame)
+			}
 			e.Data = enc.AppendKey(e.Data, ErrorStackFieldName)
-			e.Data = enc.AppendBytes(e.Data, buffer.Bytes())
-			buffer.Free()
+			if o.cfg.StructuredStack {
+				e.Data = appendArray(e.Data, frameArray(frames), o.types)
+			} else {
+				buffer := buffer.Get()
+				stackfmt := stack.NewStackFormatter(buffer)
+				stackfmt.FormatFrames(frames)
+				e.Data = enc.AppendBytes(e.Data, buffer.Bytes())
+				buffer.Free()
+			}
 		}
 	}
-	for _, field := range e.Fields[:e.FieldsLength()] {
-		e.Data = enc.AppendKey(e.Data, field.Key)
-		e.Data = appendVal(e.Data, field.Val)
-	}
+	e.Data = appendFieldList(e.Data, e.Fields[:e.FieldsLength()], o.cfg.ErrorFormat, o.types)
 	e.Data = enc.AppendEndMarker(e.Data)
 	e.Data = enc.AppendLineBreak(e.Data)
 	return e.Bytes(), nil
 }
+
+// appendCaller appends the caller key, or the split caller keys, of frame.
+func (o *JSONEncoder) appendCaller(dst []byte, frame runtime.Frame) []byte {
+	if !o.cfg.SplitCaller {
+		dst = enc.AppendKey(dst, CallerFieldName)
+		return enc.AppendString(dst, callerString(frame, o.cfg.CallerFormat, o.cfg.CallerFunction, o.cfg.CallerFormatter))
+	}
+	dst = enc.AppendKey(dst, CallerFileFieldName)
+	dst = enc.AppendString(dst, callerPath(frame, o.cfg.CallerFormat))
+	dst = enc.AppendKey(dst, CallerLineFieldName)
+	dst = enc.AppendInt(dst, frame.Line)
+	if o.cfg.CallerFunction && frame.Function != "" {
+		dst = enc.AppendKey(dst, CallerFunctionFieldName)
+		dst = enc.AppendString(dst, frame.Function)
+	}
+	return dst
+}
+
+// appendFieldList appends fields as JSON key/value pairs, nesting groups as objects.
+func appendFieldList(dst []byte, fields []Field, errorFormat ErrorFormat, types *TypeEncoders) []byte {
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f.Type == GroupType {
+			children := groupFields(fields, i)
+			i += len(children)
+			if isEmptyGroup(children) {
+				continue
+			}
+			dst = enc.AppendKey(dst, f.Key)
+			dst = enc.AppendBeginMarker(dst)
+			dst = appendFieldList(dst, children, errorFormat, types)
+			dst = enc.AppendEndMarker(dst)
+			continue
+		}
+		dst = enc.AppendKey(dst, f.Key)
+		if errorFormat == ErrorFormatStructured {
+			if err, ok := fieldError(f); ok {
+				dst = appendObject(dst, errorObject{err: err}, types)
+				continue
+			}
+		}
+		dst = appendField(dst, f, types)
+	}
+	return dst
+}
+
+var (
+	_ ObjectEncoder = (*jsonObjectEncoder)(nil)
+	_ ArrayEncoder  = (*jsonArrayEncoder)(nil)
+	_ FieldEncoder  = (*jsonValueEncoder)(nil)
+
+	jsonObjectEncoderPool = sync.Pool{New: func() any { return &jsonObjectEncoder{} }}
+	jsonArrayEncoderPool  = sync.Pool{New: func() any { return &jsonArrayEncoder{} }}
+	jsonValueEncoderPool  = sync.Pool{New: func() any { return &jsonValueEncoder{} }}
+)
+
+// appendObject appends m as a JSON object.
+func appendObject(dst []byte, m ObjectMarshaler, types *TypeEncoders) []byte {
+	dst, _ = appendObjectErr(dst, m, types)
+	return dst
+}
+
+// appendObjectErr appends m as a JSON object and returns the error reported by m.
+func appendObjectErr(dst []byte, m ObjectMarshaler, types *TypeEncoders) ([]byte, error) {
+	oe := jsonObjectEncoderPool.Get().(*jsonObjectEncoder)
+	oe.buf = enc.AppendBeginMarker(dst)
+	oe.types = types
+	err := m.MarshalLogObject(oe)
+	if err != nil {
+		oe.AddString(ErrorFieldName, err.Error())
+	}
+	dst = enc.AppendEndMarker(oe.buf)
+	oe.buf, oe.types = nil, nil
+	jsonObjectEncoderPool.Put(oe)
+	return dst, err
+}
+
+// appendArray appends m as a JSON array.
+func appendArray(dst []byte, m ArrayMarshaler, types *TypeEncoders) []byte {
+	ae := jsonArrayEncoderPool.Get().(*jsonArrayEncoder)
+	ae.buf = enc.AppendArrayStart(dst)
+	ae.types = types
+	err := m.MarshalLogArray(ae)
+	if err != nil {
+		ae.AppendString(err.Error())
+	}
+	dst = enc.AppendArrayEnd(ae.buf)
+	ae.buf, ae.types = nil, nil
+	jsonArrayEncoderPool.Put(ae)
+	return dst
+}
+
+// appendTypeEncoded appends value as rendered by the type encoder fn.
+func appendTypeEncoded(dst []byte, fn typeEncoderFunc, value any, types *TypeEncoders) []byte {
+	ve := jsonValueEncoderPool.Get().(*jsonValueEncoder)
+	ve.buf, ve.types, ve.done = dst, types, false
+	fn(ve, value)
+	if !ve.done {
+		ve.buf = enc.AppendNil(ve.buf)
+	}
+	dst = ve.buf
+	ve.buf, ve.types = nil, nil
+	jsonValueEncoderPool.Put(ve)
+	return dst
+}
+
+// jsonObjectEncoder is the ObjectEncoder used for JSON output.
+type jsonObjectEncoder struct {
+	buf   []byte
+	types *TypeEncoders
+}
+
+func (o *jsonObjectEncoder) AddString(key, val string) {
+	o.buf = enc.AppendString(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddInt(key string, val int) {
+	o.buf = enc.AppendInt(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddInt64(key string, val int64) {
+	o.buf = enc.AppendInt64(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddUint64(key string, val uint64) {
+	o.buf = enc.AppendUint64(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddFloat64(key string, val float64) {
+	o.buf = enc.AppendFloat64(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddBool(key string, val bool) {
+	o.buf = enc.AppendBool(enc.AppendKey(o.buf, key), val)
+}
+
+func (o *jsonObjectEncoder) AddDuration(key string, val time.Duration) {
+	o.buf = enc.AppendDuration(enc.AppendKey(o.buf, key), val, time.Millisecond, false)
+}
+
+func (o *jsonObjectEncoder) AddTime(key string, val time.Time) {
+	o.buf = enc.AppendTime(enc.AppendKey(o.buf, key), val, TimeFieldFormat)
+}
+
+func (o *jsonObjectEncoder) AddAny(key string, val any) {
+	o.buf = appendVal(enc.AppendKey(o.buf, key), val, o.types)
+}
+
+func (o *jsonObjectEncoder) AddObject(key string, val ObjectMarshaler) error {
+	var err error
+	o.buf, err = appendObjectErr(enc.AppendKey(o.buf, key), val, o.types)
+	return err
+}
+
+func (o *jsonObjectEncoder) AddArray(key string, val ArrayMarshaler) error {
+	o.buf = appendArray(enc.AppendKey(o.buf, key), val, o.types)
+	return nil
+}
+
+// jsonArrayEncoder is the ArrayEncoder used for JSON output.
+type jsonArrayEncoder struct {
+	buf   []byte
+	types *TypeEncoders
+}
+
+// delim appends an element separator unless the array is still empty.
+func (a *jsonArrayEncoder) delim() []byte {
+	if n := len(a.buf); n > 0 && a.buf[n-1] != '[' {
+		return enc.AppendArrayDelim(a.buf)
+	}
+	return a.buf
+}
+
+func (a *jsonArrayEncoder) AppendString(val string) {
+	a.buf = enc.AppendString(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendInt(val int) {
+	a.buf = enc.AppendInt(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendInt64(val int64) {
+	a.buf = enc.AppendInt64(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendUint64(val uint64) {
+	a.buf = enc.AppendUint64(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendFloat64(val float64) {
+	a.buf = enc.AppendFloat64(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendBool(val bool) {
+	a.buf = enc.AppendBool(a.delim(), val)
+}
+
+func (a *jsonArrayEncoder) AppendDuration(val time.Duration) {
+	a.buf = enc.AppendDuration(a.delim(), val, time.Millisecond, false)
+}
+
+func (a *jsonArrayEncoder) AppendTime(val time.Time) {
+	a.buf = enc.AppendTime(a.delim(), val, TimeFieldFormat)
+}
+
+func (a *jsonArrayEncoder) AppendAny(val any) {
+	a.buf = appendVal(a.delim(), val, a.types)
+}
+
+func (a *jsonArrayEncoder) AppendObject(val ObjectMarshaler) error {
+	var err error
+	a.buf, err = appendObjectErr(a.delim(), val, a.types)
+	return err
+}
+
+func (a *jsonArrayEncoder) AppendArray(val ArrayMarshaler) error {
+	a.buf = appendArray(a.delim(), val, a.types)
+	return nil
+}
+
+// jsonValueEncoder is the FieldEncoder used for JSON output. It keeps the first value only.
+type jsonValueEncoder struct {
+	buf   []byte
+	types *TypeEncoders
+	done  bool
+}
+
+// first reports whether no value was written yet, and marks one as written.
+func (v *jsonValueEncoder) first() bool {
+	if v.done {
+		return false
+	}
+	v.done = true
+	return true
+}
+
+func (v *jsonValueEncoder) AppendString(val string) {
+	if v.first() {
+		v.buf = enc.AppendString(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendInt(val int) {
+	if v.first() {
+		v.buf = enc.AppendInt(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendInt64(val int64) {
+	if v.first() {
+		v.buf = enc.AppendInt64(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendUint64(val uint64) {
+	if v.first() {
+		v.buf = enc.AppendUint64(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendFloat64(val float64) {
+	if v.first() {
+		v.buf = enc.AppendFloat64(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendBool(val bool) {
+	if v.first() {
+		v.buf = enc.AppendBool(v.buf, val)
+	}
+}
+
+func (v *jsonValueEncoder) AppendDuration(val time.Duration) {
+	if v.first() {
+		v.buf = enc.AppendDuration(v.buf, val, time.Millisecond, false)
+	}
+}
+
+func (v *jsonValueEncoder) AppendTime(val time.Time) {
+	if v.first() {
+		v.buf = enc.AppendTime(v.buf, val, TimeFieldFormat)
+	}
+}
+
+func (v *jsonValueEncoder) AppendAny(val any) {
+	if v.first() {
+		v.buf = appendVal(v.buf, val, v.types)
+	}
+}
+
+func (v *jsonValueEncoder) AppendObject(val ObjectMarshaler) error {
+	if !v.first() {
+		return nil
+	}
+	var err error
+	v.buf, err = appendObjectErr(v.buf, val, v.types)
+	return err
+}
+
+func (v *jsonValueEncoder) AppendArray(val ArrayMarshaler) error {
+	if v.first() {
+		v.buf = appendArray(v.buf, val, v.types)
+	}
+	return nil
+}
@@ -9,21 +9,33 @@ import (
 )
 
 var (
-	_ Logger = (*Log)(nil)
+	_ FieldLogger = (*Log)(nil)
 )
 
 // Log is an implementation of Logger interface.
 // It encapsulates default or custom logger to provide module and level based logging.
 type Log struct {
-	module     string
-	fields     []Field
-	once       sync.Once
-	writer     io.Writer
-	encoder    Encoder
-	callerLvl  uint32
-	callerSkip int
-	tracerLvl  uint32
-	level      Level
+	module      string
+	fields      []Field
+	once        sync.Once
+	writer      io.Writer
+	encoder     Encoder
+	callerLvl   uint32
+	callerSkip  int
+	tracerLvl   uint32
+	level       Level
+	writeLevel  Level
+	redactor    *redactor
+	groups      int
+	duplicates  DuplicateKeyPolicy
+	diagnostics DiagnosticsConfig
+	recent      *RingBuffer
+	ownsWriter  bool
+	fatalOpts   *fatalOptions
+	handlers    []EntryHandler
+	clock       Clock
+	stampTime   bool
+	crossed     *fingersCrossed
 }
 
 func newLogger() *Log {
@@ -62,11 +74,19 @@ func (l *Log) init() {
 
 func (l *Log) initConfig(cfg Config) error {
 	var err error
+	l.clock = SystemClock
+	if cfg.Clock != nil {
+		l.clock = cfg.Clock
+	}
 	switch cfg.Handler.Type {
 	case HandlerTypeFile:
 		l.writer, err = NewFile(cfg.Handler.File)
 	case HandlerTypeRotateFile:
-		l.writer, err = NewRotateFile(cfg.Handler.RotateFile)
+		rcfg := cfg.Handler.RotateFile
+		if rcfg.Clock == nil {
+			rcfg.Clock = cfg.Clock
+		}
+		l.writer, err = NewRotateFile(rcfg)
 	case HandlerTypeCustom:
 		l.writer = cfg.Handler.Writer
 	default:
@@ -75,11 +95,20 @@ func (l *Log) initConfig(cfg Config) error {
 	if err != nil {
 		return err
 	}
+	l.ownsWriter = cfg.Handler.Type != HandlerTypeCustom
+	// The clock is only read for the entries someone reads the time of.
+	l.stampTime = len(cfg.EntryHandlers) > 0 || cfg.Diagnostics.RecentEntries > 0
 	switch cfg.Encoding {
 	case JSONEncoding:
-		l.encoder = NewJSONEncoder(cfg.JSONEncoder)
+		je := NewJSONEncoder(cfg.JSONEncoder)
+		je.types = cfg.TypeEncoders
+		l.encoder = je
+		l.stampTime = l.stampTime || !cfg.JSONEncoder.DisableTimestamp
 	default:
-		l.encoder = NewTextEncoder(cfg.TextEncoder)
+		te := NewTextEncoder(cfg.TextEncoder)
+		te.types = cfg.TypeEncoders
+		l.encoder = te
+		l.stampTime = l.stampTime || !cfg.TextEncoder.DisableTimestamp
 	}
 	l.level = INFO // if level is not set, set it to INFO
 	if cfg.Level > 0 {
@@ -91,6 +120,28 @@ func (l *Log) initConfig(cfg Config) error {
 	for _, v := range cfg.StacktraceLevels {
 		l.tracerLvl |= uint32(v)
 	}
+	if l.redactor, err = newRedactor(cfg.Redact); err != nil {
+		return err
+	}
+	if l.duplicates, err = parseDuplicateKeyPolicy(cfg.DuplicateKeys); err != nil {
+		return err
+	}
+	if l.fatalOpts, err = newFatalOptions(cfg); err != nil {
+		return err
+	}
+	l.handlers = slices.Clone(cfg.EntryHandlers)
+	// Handlers may take entries the level of the logger filters out of its writer.
+	l.writeLevel = l.level
+	for _, level := range Levels {
+		if level > l.level && l.handlersEnabled(level) {
+			l.level = level
+		}
+	}
+	l.crossed = newFingersCrossed(cfg.FingersCrossed)
+	l.diagnostics = cfg.Diagnostics
+	if cfg.Diagnostics.RecentEntries > 0 {
+		l.recent = NewRingBuffer(RingBufferConfig{Size: cfg.Diagnostics.RecentEntries})
+	}
 	return nil
 }
 
@@ -112,7 +163,7 @@ func (l *Log) logf(level Level, format string, args ...any) {
 		return
 	}
 	msg := formatMessage(format, args...)
-	l.output(level, msg, nil, 1)
+	l.output(level, msg, nil, nil, 1)
 }
 
 // Fatalf calls underlying logger.Fatal.
@@ -121,8 +172,7 @@ func (l *Log) Fatalf(format string, args ...any) {
 		return
 	}
 	msg := formatMessage(format, args...)
-	l.output(FATAL, msg, nil, 1)
-	os.Exit(1)
+	l.output(FATAL, msg, nil, nil, 1)
 }
 
 // Panicf calls underlying logger.Panic.
@@ -131,8 +181,7 @@ func (l *Log) Panicf(format string, args ...any) {
 		return
 	}
 	msg := formatMessage(format, args...)
-	l.output(PANIC, msg, nil, 1)
-	panic(msg)
+	l.output(PANIC, msg, nil, nil, 1)
 }
 
 // Debugf calls debug log function if DEBUG level enabled.
@@ -161,8 +210,7 @@ func (l *Log) Fatal(msg string, keysAndVals ...any) {
 		return
 	}
 
-	l.output(FATAL, msg, keysAndVals, 0)
-	os.Exit(1)
+	l.output(FATAL, msg, keysAndVals, nil, 0)
 }
 
 // Panic calls underlying logger.Panic.
@@ -171,8 +219,7 @@ func (l *Log) Panic(msg string, keysAndVals ...any) {
 		return
 	}
 
-	l.output(PANIC, msg, keysAndVals, 0)
-	panic(msg)
+	l.output(PANIC, msg, keysAndVals, nil, 0)
 }
 
 // Debug calls debug log function if DEBUG level enabled.
@@ -181,7 +228,7 @@ func (l *Log) Debug(msg string, keysAndVals ...any) {
 		return
 	}
 
-	l.output(DEBUG, msg, keysAndVals, 0)
+	l.output(DEBUG, msg, keysAndVals, nil, 0)
 }
 
 // Info calls info log function if INFO level enabled.
@@ -190,7 +237,7 @@ func (l *Log) Info(msg string, keysAndVals ...any) {
 		return
 	}
 
-	l.output(INFO, msg, keysAndVals, 0)
+	l.output(INFO, msg, keysAndVals, nil, 0)
 }
 
 // Warn calls warn log function if WARNING level enabled.
@@ -199,7 +246,7 @@ func (l *Log) Warn(msg string, keysAndVals ...any) {
 		return
 	}
 
-	l.output(WARNING, msg, keysAndVals, 0)
+	l.output(WARNING, msg, keysAndVals, nil, 0)
 }
 
 // Error calls error log function if ERROR level enabled.
@@ -207,51 +254,78 @@ func (l *Log) Error(msg string, keysAndVals ...any) {
 	if l.level < ERROR {
 		return
 	}
-	l.output(ERROR, msg, keysAndVals, 0)
+	l.output(ERROR, msg, keysAndVals, nil, 0)
+}
+
+// LogFields logs a message with typed fields at the given level.
+// The fields are not boxed into interfaces, so unlike the key/value methods
+// it does not allocate for fields built with the typed constructors.
+func (l *Log) LogFields(level Level, msg string, fields ...Field) {
+	if l.level < level {
+		return
+	}
+	l.output(level, msg, nil, fields, 0)
 }
 
 // WithValues returns a logger configured with the key-value pairs.
 func (l *Log) WithValues(keysAndVals ...any) Logger {
 	clone := l.clone()
-	for i := 0; i+1 < len(keysAndVals); i += 2 {
-		key, val := keysAndVals[i], keysAndVals[i+1]
-		keyStr, isString := key.(string)
-		if !isString {
-			fmt.Fprintf(os.Stderr, "golog: WithValues received non-string key: %v, ignoring remaining args\n", key)
-			break
+	var i int
+	clone.fields, i = appendFields(clone.fields, keysAndVals, false)
+	if i < len(keysAndVals) {
+		if _, isString := keysAndVals[i].(string); isString {
+			fmt.Fprintf(os.Stderr, "golog: WithValues received odd number of arguments, ignoring last key: %v\n", keysAndVals[i])
+		} else {
+			fmt.Fprintf(os.Stderr, "golog: WithValues received non-string key: %v, ignoring remaining args\n", keysAndVals[i])
 		}
-
-		clone.fields = append(clone.fields, field(keyStr, val))
 	}
-	if len(keysAndVals)%2 != 0 {
-		fmt.Fprintf(os.Stderr, "golog: WithValues received odd number of arguments, ignoring last key: %v\n", keysAndVals[len(keysAndVals)-1])
+	return clone
+}
+
+// WithGroup returns a logger that nests all fields added after this call,
+// including the ones given at the call site, under the group name.
+func (l *Log) WithGroup(name string) FieldLogger {
+	if name == "" {
+		return l
 	}
+	clone := l.clone()
+	clone.fields = append(clone.fields, Field{Key: name, Type: groupOpenType})
+	clone.groups++
 	return clone
 }
 
-func (l *Log) output(level Level, msg string, args []any, extraCallerSkip int) { //nolint:funlen
+func (l *Log) output(level Level, msg string, args []any, fields []Field, extraCallerSkip int) { //nolint:funlen
 	e := acquireEntry()
 	defer releaseEntry(e)
+	if l.stampTime {
+		e.Time = l.clock.Now()
+	}
 	e.Module = l.module
 
-	n := 0
 	for _, f := range l.fields {
-		e.Fields = append(e.Fields, f)
-		n++
-	}
-	for i := 0; i+1 < len(args); i += 2 {
-		key, val := args[i], args[i+1]
-		keyStr, isString := key.(string)
-		if !isString {
-			break
-		}
-
-		e.Fields = append(e.Fields, field(keyStr, val))
-		n++
+		e.Fields = appendFlat(e.Fields, f)
+	}
+	e.Fields, _ = appendFields(e.Fields, args, true)
+	for _, f := range fields {
+		e.Fields = appendFlat(e.Fields, f)
 	}
-	e.SetFieldsLen(n)
+	if l.groups > 0 {
+		closeGroups(e.Fields)
+	}
+	e.Fields = mergeErrorFields(e.Fields)
+	e.Fields = dedupeFields(e.Fields, l.duplicates)
+	// Entries below the level of the writer are only logged for the handlers taking them.
+	written := level <= l.writeLevel
+	fatal := written && (level == FATAL || level == PANIC)
+	if fatal && l.diagnostics.enabled() {
+		e.Fields = l.appendDiagnostics(e.Fields)
+	}
+	e.SetFieldsLen(len(e.Fields))
 
 	e.Message = msg
+	if l.redactor != nil {
+		l.redactor.redactEntry(e)
+	}
 	e.Level = level
 	e.SetCallerSkip(l.callerSkip + extraCallerSkip)
 
@@ -261,16 +335,75 @@ func (l *Log) output(level Level, msg string, args []any, extraCallerSkip int) {
 	if l.isStacktraceEnabled(e.Level) {
 		e.SetFlag(FlagStacktrace)
 	}
-	b, err := l.encoder.Encode(e)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "golog: failed to encode log: %v\n", err)
-		return
+	var b []byte
+	// Entries only taken by handlers reading their fields are not encoded.
+	if written || l.handlersNeedData(level) {
+		var err error
+		if b, err = l.encoder.Encode(e); err != nil {
+			fmt.Fprintf(os.Stderr, "golog: failed to encode log: %v\n", err)
+			if fatal {
+				l.fatalOpts.fatal(level, e.Message, e.Fields[:e.FieldsLength()])
+			}
+			return
+		}
+	}
+	if written {
+		l.write(e, b)
+	}
+	if len(l.handlers) > 0 {
+		l.handle(e)
+	}
+	if fatal {
+		// The process is about to exit or unwind: do not leave the entry in a buffer.
+		if err := flushWriter(l.writer); err != nil {
+			fmt.Fprintf(os.Stderr, "golog: failed to flush log: %v\n", err)
+		}
+		l.fatalOpts.fatal(level, e.Message, e.Fields[:e.FieldsLength()])
+	}
+}
+
+// write records the entry e, encoded as b, as recent and writes it.
+func (l *Log) write(e *Entry, b []byte) {
+	level := e.Level
+	if l.recent != nil {
+		_ = l.recent.Handle(e)
 	}
-	if _, err := l.writer.Write(b); err != nil {
+	var err error
+	if l.crossed != nil {
+		err = l.crossed.write(l.writer, level, b)
+	} else {
+		_, err = l.writer.Write(b)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "golog: failed to write log: %v\n", err)
 	}
 }
 
+// flushWriter flushes w if it buffers data.
+func flushWriter(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Sync() error }:
+		return f.Sync()
+	case interface{ Flush() error }:
+		return f.Flush()
+	}
+	return nil
+}
+
+// Sync flushes the data buffered by the writer of the logger.
+func (l *Log) Sync() error {
+	return flushWriter(l.writer)
+}
+
+// Close flushes the writer of the logger and closes it if golog opened it,
+// as for the file and rotateFile handlers. The logger must not be used afterwards.
+func (l *Log) Close() error {
+	if w, ok := l.writer.(syncCloser); ok && l.ownsWriter {
+		return w.Close()
+	}
+	return l.Sync()
+}
+
 func (l *Log) isCallerEnabled(level Level) bool {
 	return l.callerLvl&uint32(level) == uint32(level)
 }
@@ -283,14 +416,26 @@ func (l *Log) isStacktraceEnabled(level Level) bool {
 func (l *Log) clone() *Log {
 	fields := slices.Clone(l.fields)
 	return &Log{
-		level:      l.level,
-		module:     l.module,
-		writer:     l.writer,
-		fields:     fields,
-		encoder:    l.encoder,
-		callerLvl:  l.callerLvl,
-		callerSkip: l.callerSkip,
-		tracerLvl:  l.tracerLvl,
-		once:       sync.Once{},
+		level:       l.level,
+		writeLevel:  l.writeLevel,
+		module:      l.module,
+		writer:      l.writer,
+		fields:      fields,
+		encoder:     l.encoder,
+		callerLvl:   l.callerLvl,
+		callerSkip:  l.callerSkip,
+		tracerLvl:   l.tracerLvl,
+		redactor:    l.redactor,
+		groups:      l.groups,
+		duplicates:  l.duplicates,
+		diagnostics: l.diagnostics,
+		recent:      l.recent,
+		ownsWriter:  l.ownsWriter,
+		fatalOpts:   l.fatalOpts,
+		handlers:    l.handlers,
+		clock:       l.clock,
+		stampTime:   l.stampTime,
+		crossed:     l.crossed,
+		once:        sync.Once{},
 	}
 }
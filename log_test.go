@@ -1,7 +1,9 @@
 package golog_test
 
 import (
+	"context"
 	"encoding/json"
+	"io"
 	"sync"
 	"testing"
 	"time"
@@ -24,6 +26,24 @@ var (
 	fakeDisableStacktrace = true
 )
 
+// newTestLogger returns a logger named module configured with cfg, without timestamp nor
+// colors, writing its entries to w if not nil. The encoding is JSON unless set in cfg.
+func newTestLogger(t *testing.T, module string, w io.Writer, cfg golog.Config) *golog.Log {
+	t.Helper()
+	if cfg.Encoding == "" {
+		cfg.Encoding = golog.JSONEncoding
+	}
+	cfg.JSONEncoder.DisableTimestamp = true
+	cfg.TextEncoder.DisableTimestamp = true
+	cfg.TextEncoder.DisableColor = true
+	if w != nil {
+		cfg.Handler = golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: w}
+	}
+	log, err := golog.NewLoggerByConfig(module, cfg)
+	require.NoError(t, err)
+	return log
+}
+
 func makeFields() []interface{} {
 	return []interface{}{
 		"a", 1,
@@ -178,6 +198,14 @@ func TestWithValues_CallerSkip(t *testing.T) {
 	buf.Reset()
 	l.WithValues("k", "v").Info("with values")
 	require.Contains(buf.String(), "log_test.go", "WithValues should preserve callerSkip")
+
+	buf.Reset()
+	golog.WithValues("k", "v").Info("global with values")
+	require.Contains(buf.String(), "log_test.go")
+
+	buf.Reset()
+	golog.LoggerFromContext(context.Background()).Info("from context")
+	require.Contains(buf.String(), "log_test.go")
 }
 
 func TestWithValues_ChainRace(t *testing.T) {
@@ -216,6 +244,31 @@ func TestWithValues_ChainRace(t *testing.T) {
 	wg.Wait()
 }
 
+func TestLog_ClockReads(t *testing.T) {
+	require := require.New(t)
+	var reads int
+	clock := golog.ClockFunc(func() time.Time {
+		reads++
+		return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	})
+
+	newTestLogger(t, "clock", io.Discard, golog.Config{Clock: clock}).Info("untimed")
+	require.Zero(reads)
+
+	ring := golog.NewRingBuffer(golog.RingBufferConfig{Size: 1})
+	newTestLogger(t, "clock", io.Discard, golog.Config{Clock: clock, EntryHandlers: []golog.EntryHandler{ring}}).Info("handled")
+	require.Equal(1, reads)
+	require.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ring.Snapshot()[0].Time)
+
+	log, err := golog.NewLoggerByConfig("clock", golog.Config{
+		Clock:   clock,
+		Handler: golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: io.Discard},
+	})
+	require.NoError(err)
+	log.Info("timed")
+	require.Equal(2, reads)
+}
+
 func TestDebugLog(t *testing.T) {
 	t.Skip()
 	defer resetConfigs()
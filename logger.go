@@ -40,6 +40,12 @@ const (
 	// CallerFieldName is the field name used for caller field.
 	CallerFieldName = "caller"
 
+	// CallerFileFieldName, CallerLineFieldName and CallerFunctionFieldName are the field names
+	// used for the caller when JSONEncoderConfig.SplitCaller is set.
+	CallerFileFieldName     = "file"
+	CallerLineFieldName     = "line"
+	CallerFunctionFieldName = "function"
+
 	// ErrorStackFieldName is the field name used for error stacks.
 	ErrorStackFieldName = "stack"
 
@@ -49,15 +55,10 @@ const (
 	TimeFieldFormat = time.RFC3339
 )
 
-// Field is a key/value pair.
-type Field struct {
-	Key string
-	Val any
-}
-
 // Logger represents a general-purpose logger.
 type Logger interface {
 	WithValues(keysAndVals ...any) Logger
+	WithGroup(name string) FieldLogger
 	Panicf(msg string, args ...any)
 	Fatalf(msg string, args ...any)
 	Errorf(msg string, args ...any)
@@ -72,6 +73,53 @@ type Logger interface {
 	Debug(msg string, keysAndVals ...any)
 }
 
+// FieldLogger is a Logger that also logs typed fields without boxing them.
+// Log implements it; check for it on a Logger with a type assertion.
+type FieldLogger interface {
+	Logger
+	LogFields(level Level, msg string, fields ...Field)
+}
+
+// LogFieldsTo logs a message with typed fields at the given level using logger.
+// Loggers that do not implement FieldLogger receive the fields as key/value pairs.
+func LogFieldsTo(logger Logger, level Level, msg string, fields ...Field) {
+	logFieldsTo(logger, level, msg, fields)
+}
+
+// logFieldsTo is LogFieldsTo for the functions logging on behalf of their caller,
+// which a Log reports as the caller of the entry.
+func logFieldsTo(logger Logger, level Level, msg string, fields []Field) {
+	switch l := logger.(type) {
+	case *Log:
+		// Called directly, output resolves the caller of the function calling this one.
+		if l.level >= level {
+			l.output(level, msg, nil, fields, 1)
+		}
+		return
+	case FieldLogger:
+		l.LogFields(level, msg, fields...)
+		return
+	}
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		args[i] = f
+	}
+	switch level {
+	case PANIC:
+		logger.Panic(msg, args...)
+	case FATAL:
+		logger.Fatal(msg, args...)
+	case ERROR:
+		logger.Error(msg, args...)
+	case WARNING:
+		logger.Warn(msg, args...)
+	case INFO:
+		logger.Info(msg, args...)
+	default:
+		logger.Debug(msg, args...)
+	}
+}
+
 // Encoder is an interface for encoding log entry.
 type Encoder interface {
 	Encode(*Entry) ([]byte, error)
@@ -0,0 +1,81 @@
+package golog
+
+import (
+	"time"
+)
+
+// ObjectMarshaler is implemented by types that can encode themselves as a set of
+// key/value pairs, without going through reflection.
+// JSONEncoder renders them as a nested object and TextEncoder as dotted keys.
+// An error returned by MarshalLogObject is recorded inside the object under ErrorFieldName.
+type ObjectMarshaler interface {
+	MarshalLogObject(ObjectEncoder) error
+}
+
+// ObjectMarshalerFunc is a func adapter that implements ObjectMarshaler.
+type ObjectMarshalerFunc func(ObjectEncoder) error
+
+// MarshalLogObject calls f(enc).
+func (f ObjectMarshalerFunc) MarshalLogObject(enc ObjectEncoder) error {
+	return f(enc)
+}
+
+// ArrayMarshaler is implemented by types that can encode themselves as an array,
+// without going through reflection.
+type ArrayMarshaler interface {
+	MarshalLogArray(ArrayEncoder) error
+}
+
+// ArrayMarshalerFunc is a func adapter that implements ArrayMarshaler.
+type ArrayMarshalerFunc func(ArrayEncoder) error
+
+// MarshalLogArray calls f(enc).
+func (f ArrayMarshalerFunc) MarshalLogArray(enc ArrayEncoder) error {
+	return f(enc)
+}
+
+// ObjectEncoder writes the key/value pairs of an ObjectMarshaler straight into the entry.
+type ObjectEncoder interface {
+	AddString(key, val string)
+	AddInt(key string, val int)
+	AddInt64(key string, val int64)
+	AddUint64(key string, val uint64)
+	AddFloat64(key string, val float64)
+	AddBool(key string, val bool)
+	AddDuration(key string, val time.Duration)
+	AddTime(key string, val time.Time)
+	AddAny(key string, val any)
+	AddObject(key string, val ObjectMarshaler) error
+	AddArray(key string, val ArrayMarshaler) error
+}
+
+// ArrayEncoder writes the elements of an ArrayMarshaler straight into the entry.
+type ArrayEncoder interface {
+	AppendString(val string)
+	AppendInt(val int)
+	AppendInt64(val int64)
+	AppendUint64(val uint64)
+	AppendFloat64(val float64)
+	AppendBool(val bool)
+	AppendDuration(val time.Duration)
+	AppendTime(val time.Time)
+	AppendAny(val any)
+	AppendObject(val ObjectMarshaler) error
+	AppendArray(val ArrayMarshaler) error
+}
+
+// Object constructs a field with the given key and ObjectMarshaler.
+func Object(key string, val ObjectMarshaler) Field {
+	if val == nil {
+		return Any(key, nil)
+	}
+	return Field{Key: key, Type: ObjectMarshalerType, Val: val}
+}
+
+// Array constructs a field with the given key and ArrayMarshaler.
+func Array(key string, val ArrayMarshaler) Field {
+	if val == nil {
+		return Any(key, nil)
+	}
+	return Field{Key: key, Type: ArrayMarshalerType, Val: val}
+}
@@ -0,0 +1,130 @@
+package golog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+type testAddress struct {
+	City string
+	Zip  int
+}
+
+func (a *testAddress) MarshalLogObject(enc golog.ObjectEncoder) error {
+	enc.AddString("city", a.City)
+	enc.AddInt("zip", a.Zip)
+	return nil
+}
+
+type testUser struct {
+	ID      int64
+	Name    string
+	Admin   bool
+	Address *testAddress
+	Roles   testRoles
+	Created time.Time
+}
+
+func (u *testUser) MarshalLogObject(enc golog.ObjectEncoder) error {
+	enc.AddInt64("id", u.ID)
+	enc.AddString("name", u.Name)
+	enc.AddBool("admin", u.Admin)
+	if err := enc.AddObject("address", u.Address); err != nil {
+		return err
+	}
+	if err := enc.AddArray("roles", &u.Roles); err != nil {
+		return err
+	}
+	enc.AddTime("created", u.Created)
+	return nil
+}
+
+type testRoles []string
+
+func (r *testRoles) MarshalLogArray(enc golog.ArrayEncoder) error {
+	for _, v := range *r {
+		enc.AppendString(v)
+	}
+	return nil
+}
+
+var benchUser = &testUser{
+	ID:      42,
+	Name:    "john doe",
+	Admin:   true,
+	Address: &testAddress{City: "Paris", Zip: 75001},
+	Roles:   testRoles{"read", "write"},
+	Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestObjectMarshaler_JSON(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	golog.Info("login", "user", benchUser)
+	require.True(validJSON(buf.String()))
+	require.Equal(`{"level":"info","message":"login","user":{"id":42,"name":"john doe","admin":true,`+
+		`"address":{"city":"Paris","zip":75001},"roles":["read","write"],"created":"2024-01-02T03:04:05Z"}}`+"\n", buf.String())
+
+	buf.Reset()
+	golog.Info("typed", golog.Object("user", benchUser), golog.Array("roles", &testRoles{}))
+	require.Contains(buf.String(), `"user":{"id":42,`)
+	require.Contains(buf.String(), `"roles":[]`)
+
+	buf.Reset()
+	failing := golog.ObjectMarshalerFunc(func(enc golog.ObjectEncoder) error {
+		enc.AddString("a", "b")
+		return errors.New("oops")
+	})
+	golog.Info("failing", "obj", failing)
+	require.True(validJSON(buf.String()))
+	require.Contains(buf.String(), `"obj":{"a":"b","error":"oops"}`)
+}
+
+func TestObjectMarshaler_Text(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+
+	golog.Info("login", "user", benchUser, "n", 1)
+	require.Equal(`INFO login user.id=42 user.name="john doe" user.admin=true user.address.city=Paris `+
+		`user.address.zip=75001 user.roles=["read","write"] user.created=2024-01-02T03:04:05Z n=1`+"\n", buf.String())
+
+	buf.Reset()
+	golog.Info("array", golog.Array("roles", &testRoles{"a"}))
+	require.Equal(`INFO array roles=["a"]`+"\n", buf.String())
+}
+
+func TestObjectMarshaler_NoAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted under the race detector")
+	}
+	cfg := golog.Config{
+		Level:    golog.INFO,
+		Encoding: golog.JSONEncoding,
+		Handler: golog.HandlerConfig{
+			Type: golog.HandlerTypeFile,
+			File: golog.FileConfig{Path: ""},
+		},
+	}
+	for _, encoding := range []golog.Encoding{golog.JSONEncoding, golog.TextEncoding} {
+		cfg.Encoding = encoding
+		log, err := golog.NewLoggerByConfig("alloc", cfg)
+		require.NoError(t, err)
+		allocs := testing.AllocsPerRun(100, func() {
+			log.LogFields(golog.INFO, "login", golog.Object("user", benchUser))
+		})
+		require.Zero(t, allocs, encoding)
+	}
+}
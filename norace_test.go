@@ -0,0 +1,7 @@
+//go:build !race
+
+package golog_test
+
+// raceEnabled reports whether the race detector is on. It allocates on its own,
+// so allocation counts are not checked under it.
+const raceEnabled = false
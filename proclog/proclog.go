@@ -0,0 +1,285 @@
+// Package proclog logs the output of child processes with golog, one entry per line.
+//
+//	cmd := exec.Command("worker", "--once")
+//	err := proclog.Run(cmd, proclog.Config{Logger: logger, DetectLevel: true})
+//
+// The entries carry the pid, the command and the stream of the lines. Lines holding a
+// JSON object are logged with its fields, and Go panic traces as single entries.
+package proclog
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/millken/golog"
+)
+
+// Field names of the entries.
+const (
+	PIDFieldName      = "pid"
+	CmdFieldName      = "cmd"
+	StreamFieldName   = "stream"
+	ExitCodeFieldName = "exitCode"
+	DurationFieldName = "duration"
+)
+
+// Names of the streams of the entries.
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// ExitMessage is the message of the entry logged when the process ends.
+const ExitMessage = "exited"
+
+// Config is the configuration of the logged processes.
+type Config struct {
+	// Logger logs the output. The global logger is used if not set.
+	Logger golog.Logger
+	// StdoutLevel is the level of the lines of stdout, INFO if not set.
+	StdoutLevel golog.Level
+	// StderrLevel is the level of the lines of stderr, WARNING if not set.
+	StderrLevel golog.Level
+	// DetectLevel takes the level of a line from a prefix such as "[ERROR]", see golog.ParseLevelPrefix.
+	DetectLevel bool
+	// ErrorLevel is the level of the panics and of the failed exits, ERROR if not set.
+	ErrorLevel golog.Level
+	// Clock tells the duration of the process, golog.SystemClock if not set.
+	Clock golog.Clock
+}
+
+// Process logs the output of a command.
+type Process struct {
+	cmd    *exec.Cmd
+	cfg    Config
+	name   string
+	stdout *lineWriter
+	stderr *lineWriter
+	start  time.Time
+}
+
+// Attach sets the stdout and stderr of cmd to log their lines. It must be called before
+// cmd is started, then the process must be started and waited for with the returned Process.
+func Attach(cmd *exec.Cmd, cfg Config) *Process {
+	if cfg.StdoutLevel == 0 {
+		cfg.StdoutLevel = golog.INFO
+	}
+	if cfg.StderrLevel == 0 {
+		cfg.StderrLevel = golog.WARNING
+	}
+	if cfg.ErrorLevel == 0 {
+		cfg.ErrorLevel = golog.ERROR
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = golog.SystemClock
+	}
+	p := &Process{cmd: cmd, cfg: cfg, name: filepath.Base(cmd.Path)}
+	if len(cmd.Args) > 0 {
+		p.name = filepath.Base(cmd.Args[0])
+	}
+	p.stdout = newLineWriter(p, Stdout, cfg.StdoutLevel)
+	p.stderr = newLineWriter(p, Stderr, cfg.StderrLevel)
+	cmd.Stdout = p.stdout
+	cmd.Stderr = p.stderr
+	return p
+}
+
+// Run attaches to cmd, starts it and waits for it to end.
+func Run(cmd *exec.Cmd, cfg Config) error {
+	return Attach(cmd, cfg).Run()
+}
+
+// Start starts the command.
+func (p *Process) Start() error {
+	p.start = p.cfg.Clock.Now()
+	return p.cmd.Start()
+}
+
+// Wait waits for the command to end, then logs its last lines and its exit status.
+func (p *Process) Wait() error {
+	err := p.cmd.Wait()
+	p.stdout.Close()
+	p.stderr.Close()
+
+	level := golog.INFO
+	fields := append(p.fields(), golog.Duration(DurationFieldName, p.cfg.Clock.Now().Sub(p.start)))
+	if p.cmd.ProcessState != nil {
+		fields = append(fields, golog.Int(ExitCodeFieldName, p.cmd.ProcessState.ExitCode()))
+	}
+	if err != nil {
+		level = p.cfg.ErrorLevel
+		fields = append(fields, golog.Err(err))
+	}
+	p.log(level, ExitMessage, fields...)
+	return err
+}
+
+// Run starts the command and waits for it to end.
+func (p *Process) Run() error {
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return p.Wait()
+}
+
+// fields returns the fields identifying the process.
+func (p *Process) fields() []golog.Field {
+	pid := 0
+	if p.cmd.Process != nil {
+		pid = p.cmd.Process.Pid
+	}
+	return []golog.Field{golog.Int(PIDFieldName, pid), golog.String(CmdFieldName, p.name)}
+}
+
+func (p *Process) log(level golog.Level, msg string, fields ...golog.Field) {
+	// Entries of the process never exit nor panic.
+	if level < golog.ERROR {
+		level = golog.ERROR
+	}
+	if p.cfg.Logger == nil {
+		golog.LogFields(level, msg, fields...)
+		return
+	}
+	golog.LogFieldsTo(p.cfg.Logger, level, msg, fields...)
+}
+
+// lineWriter logs the lines written to a stream of the process, split by a golog.LineWriter.
+type lineWriter struct {
+	*golog.LineWriter
+	p      *Process
+	stream string
+	level  golog.Level
+	// trace holds the lines of a panic trace being written, afterBlank whether its last line
+	// is blank and inGoroutine whether it reached the goroutines.
+	trace       []string
+	afterBlank  bool
+	inGoroutine bool
+}
+
+func newLineWriter(p *Process, stream string, level golog.Level) *lineWriter {
+	w := &lineWriter{p: p, stream: stream, level: level}
+	w.LineWriter = golog.NewLineFuncWriter(w.line)
+	return w
+}
+
+// Close logs the last line if it is incomplete, and the pending panic trace.
+// It must be called once the process stopped writing.
+func (w *lineWriter) Close() error {
+	err := w.LineWriter.Close()
+	w.flushTrace()
+	return err
+}
+
+func (w *lineWriter) line(line string) {
+	if w.trace != nil {
+		if w.traceGoesOn(line) {
+			w.trace = append(w.trace, line)
+			w.afterBlank = line == ""
+			w.inGoroutine = w.inGoroutine || strings.HasPrefix(line, "goroutine ")
+			return
+		}
+		w.flushTrace()
+	}
+	if strings.HasPrefix(line, "panic: ") || strings.HasPrefix(line, "fatal error: ") {
+		w.trace = []string{line}
+		return
+	}
+	if line == "" {
+		return
+	}
+
+	fields := append(w.p.fields(), golog.String(StreamFieldName, w.stream))
+	if msg, level, jsonFields, ok := parseJSON(line); ok {
+		if level == 0 {
+			level = w.level
+		}
+		w.p.log(level, msg, append(fields, jsonFields...)...)
+		return
+	}
+	level, msg := w.level, line
+	if w.p.cfg.DetectLevel {
+		if l, rest, ok := golog.ParseLevelPrefix(line); ok {
+			level, msg = l, rest
+		}
+	}
+	w.p.log(level, msg, fields...)
+}
+
+// traceGoesOn returns whether line is part of the panic trace being written: blank lines,
+// goroutines after blank lines, the lines of the goroutines, and the indented or bracketed
+// lines of the header, as in "\tpanic: boom" or "[signal SIGSEGV: ...]".
+func (w *lineWriter) traceGoesOn(line string) bool {
+	switch {
+	case line == "" || w.afterBlank && strings.HasPrefix(line, "goroutine "):
+		return true
+	case w.afterBlank:
+		return false
+	case w.inGoroutine:
+		return true
+	default:
+		return strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "[")
+	}
+}
+
+// flushTrace logs the pending panic trace as a single entry.
+func (w *lineWriter) flushTrace() {
+	if w.trace == nil {
+		return
+	}
+	msg := strings.TrimRight(strings.Join(w.trace, "\n"), "\n")
+	w.trace, w.afterBlank, w.inGoroutine = nil, false, false
+	w.p.log(w.p.cfg.ErrorLevel, msg, append(w.p.fields(), golog.String(StreamFieldName, w.stream))...)
+}
+
+// JSON keys of the message and of the level of the lines holding a JSON object. The keys
+// of the time are dropped, the entries having their own.
+var (
+	jsonMessageKeys = []string{"msg", "message"}
+	jsonLevelKeys   = []string{"level", "lvl", "severity"}
+	jsonTimeKeys    = []string{"time", "ts", "timestamp"}
+)
+
+// parseJSON parses a line holding a JSON object, returning its message, its level or 0,
+// and its other members as fields sorted by key.
+func parseJSON(line string) (msg string, level golog.Level, fields []golog.Field, ok bool) {
+	if !strings.HasPrefix(line, "{") {
+		return "", 0, nil, false
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return "", 0, nil, false
+	}
+	for _, k := range jsonMessageKeys {
+		if s, ok := obj[k].(string); ok {
+			msg = s
+			delete(obj, k)
+			break
+		}
+	}
+	for _, k := range jsonLevelKeys {
+		if s, ok := obj[k].(string); ok {
+			if l, _, ok := golog.ParseLevelPrefix("[" + s + "]"); ok {
+				level = l
+				delete(obj, k)
+			}
+			break
+		}
+	}
+	for _, k := range jsonTimeKeys {
+		delete(obj, k)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields = make([]golog.Field, len(keys))
+	for i, k := range keys {
+		fields[i] = golog.Any(k, obj[k])
+	}
+	return msg, level, fields, true
+}
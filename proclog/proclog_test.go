@@ -0,0 +1,120 @@
+package proclog_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/millken/golog/proclog"
+	"github.com/stretchr/testify/require"
+)
+
+const helperEnv = "PROCLOG_HELPER"
+
+// TestMain runs the test binary as the child process when helperEnv is set.
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperEnv) {
+	case "":
+		os.Exit(m.Run())
+	case "lines":
+		fmt.Println("starting")
+		fmt.Println(`{"level":"warn","msg":"cache miss","time":"2024-01-02T00:00:00Z","key":"users","hits":3}`)
+		fmt.Fprintln(os.Stderr, "[ERROR] connection refused")
+		fmt.Fprint(os.Stdout, "no newline")
+	case "panic":
+		fmt.Println("working")
+		panic("boom")
+	case "exit":
+		os.Exit(3)
+	}
+	os.Exit(0)
+}
+
+func helperCommand(mode string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), helperEnv+"="+mode)
+	return cmd
+}
+
+func TestRun(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "proc")
+	clock := gologtest.NewClock(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	cmd := helperCommand("lines")
+	p := proclog.Attach(cmd, proclog.Config{Logger: log, DetectLevel: true, Clock: clock})
+	require.NoError(p.Start())
+	clock.Add(time.Second)
+	require.NoError(p.Wait())
+
+	entries := obs.All()
+	require.Len(entries, 5)
+	name := filepath.Base(os.Args[0])
+	for _, e := range entries {
+		require.Equal(int64(cmd.Process.Pid), e.FieldMap()[proclog.PIDFieldName])
+		require.Equal(name, e.FieldMap()[proclog.CmdFieldName])
+	}
+
+	stdout := entries.FilterField(proclog.StreamFieldName, proclog.Stdout)
+	require.Equal([]string{"starting", "cache miss", "no newline"}, stdout.Messages())
+	require.Equal(golog.INFO, stdout[0].Level)
+	require.Equal(golog.WARNING, stdout[1].Level)
+	require.Equal(map[string]any{
+		proclog.PIDFieldName:    int64(cmd.Process.Pid),
+		proclog.CmdFieldName:    name,
+		proclog.StreamFieldName: proclog.Stdout,
+		"hits":                  float64(3),
+		"key":                   "users",
+	}, stdout[1].FieldMap())
+
+	stderr := entries.FilterField(proclog.StreamFieldName, proclog.Stderr)
+	require.Equal([]string{"connection refused"}, stderr.Messages())
+	require.Equal(golog.ERROR, stderr[0].Level)
+
+	exit := entries[4]
+	require.Equal(proclog.ExitMessage, exit.Message)
+	require.Equal(golog.INFO, exit.Level)
+	require.Equal(int64(0), exit.FieldMap()[proclog.ExitCodeFieldName])
+	require.Equal(time.Second, exit.FieldMap()[proclog.DurationFieldName])
+}
+
+func TestRun_Panic(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "proc")
+	require.Error(proclog.Run(helperCommand("panic"), proclog.Config{Logger: log}))
+
+	entries := obs.All()
+	require.Len(entries, 3)
+	require.Equal("working", entries[0].Message)
+
+	trace := entries.FilterField(proclog.StreamFieldName, proclog.Stderr)
+	require.Equal(1, trace.Len())
+	require.Equal(golog.ERROR, trace[0].Level)
+	msg := trace[0].Message
+	require.True(strings.HasPrefix(msg, "panic: boom\n\ngoroutine 1 [running]:\n"), msg)
+	require.Contains(msg, "proclog_test.TestMain(")
+
+	exit := entries[2]
+	require.Equal(golog.ERROR, exit.Level)
+	require.Equal(int64(2), exit.FieldMap()[proclog.ExitCodeFieldName])
+}
+
+func TestRun_ExitCode(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "proc")
+	err := proclog.Run(helperCommand("exit"), proclog.Config{Logger: log, ErrorLevel: golog.WARNING})
+	require.EqualError(t, err, "exit status 3")
+	gologtest.AssertLogged(t, obs, golog.WARNING, proclog.ExitMessage, proclog.ExitCodeFieldName, 3)
+}
+
+func TestRun_NotStarted(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "proc")
+	err := proclog.Run(exec.Command(filepath.Join(t.TempDir(), "missing")), proclog.Config{Logger: log})
+	require.Error(t, err)
+	require.Zero(t, obs.Len())
+}
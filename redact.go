@@ -0,0 +1,406 @@
+package golog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RedactMode defines how redacted values are replaced.
+type RedactMode string
+
+const (
+	// RedactMask replaces redacted values with "***".
+	RedactMask RedactMode = "mask"
+	// RedactHash replaces redacted values with a short SHA-256 digest, so equal values can still be correlated.
+	RedactHash RedactMode = "hash"
+
+	redactedMask = "***"
+)
+
+// redactPresets are the named patterns that can be listed in RedactConfig.Presets.
+var redactPresets = map[string]string{
+	"email":       `[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`,
+	"creditCard":  `\b(?:\d[ \-]?){12,18}\d\b`,
+	"bearerToken": `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`,
+}
+
+// RedactConfig is the configuration for redacting sensitive data before it is encoded.
+type RedactConfig struct {
+	// Keys are field names whose values are always redacted. They are matched
+	// case-insensitively and may be path.Match globs such as "*token*". They also apply to
+	// the keys of maps with string keys and of ObjectMarshalers, but not to struct fields
+	// or the elements of arrays.
+	Keys []string `json:"keys" yaml:"keys"`
+	// Patterns are regular expressions; matches inside the message and the string, []byte,
+	// error and fmt.Stringer values, including the ones of maps and ObjectMarshalers, are redacted.
+	Patterns []string `json:"patterns" yaml:"patterns"`
+	// Presets are named patterns: email, creditCard and bearerToken.
+	Presets []string `json:"presets" yaml:"presets"`
+	// Mode is mask (default) or hash.
+	Mode RedactMode `json:"mode" yaml:"mode"`
+}
+
+// SecretValue wraps a value that must never be logged in clear.
+// It is rendered as "***", or as a digest when the logger redacts in hash mode.
+type SecretValue struct {
+	val any
+}
+
+// Secret wraps v so that it is redacted wherever it is logged.
+func Secret(v any) SecretValue {
+	return SecretValue{val: v}
+}
+
+// String implements fmt.Stringer.
+func (SecretValue) String() string {
+	return redactedMask
+}
+
+// MarshalJSON implements json.Marshaler.
+func (SecretValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redactedMask + `"`), nil
+}
+
+// redactor replaces sensitive field values and message fragments of an entry.
+type redactor struct {
+	keys     map[string]struct{}
+	globs    []string
+	patterns []*regexp.Regexp
+	mode     RedactMode
+}
+
+// newRedactor returns the redactor described by cfg, or nil if cfg redacts nothing.
+func newRedactor(cfg RedactConfig) (*redactor, error) {
+	r := &redactor{
+		keys: make(map[string]struct{}, len(cfg.Keys)),
+		mode: cfg.Mode,
+	}
+	switch r.mode {
+	case "":
+		r.mode = RedactMask
+	case RedactMask, RedactHash:
+	default:
+		return nil, fmt.Errorf("unknown redact mode: %s", cfg.Mode)
+	}
+	for _, k := range cfg.Keys {
+		k = strings.ToLower(k)
+		if strings.ContainsAny(k, `*?[\`) {
+			if _, err := path.Match(k, ""); err != nil {
+				return nil, fmt.Errorf("invalid redact key %q: %w", k, err)
+			}
+			r.globs = append(r.globs, k)
+			continue
+		}
+		r.keys[k] = struct{}{}
+	}
+	for _, name := range cfg.Presets {
+		expr, ok := redactPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown redact preset: %s", name)
+		}
+		r.patterns = append(r.patterns, regexp.MustCompile(expr))
+	}
+	for _, expr := range cfg.Patterns {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	if len(r.keys) == 0 && len(r.globs) == 0 && len(r.patterns) == 0 && r.mode == RedactMask {
+		return nil, nil
+	}
+	return r, nil
+}
+
+// redactEntry redacts the message and fields of e in place.
+func (r *redactor) redactEntry(e *Entry) {
+	if len(r.patterns) > 0 {
+		e.Message = r.redactString(e.Message)
+	}
+	for i := range e.Fields {
+		r.redactField(&e.Fields[i])
+	}
+}
+
+func (r *redactor) redactField(f *Field) {
+	if f.Type == GroupType {
+		return
+	}
+	if r.matchKey(f.Key) {
+		*f = String(f.Key, r.replacement(fieldString(*f)))
+		return
+	}
+	if s, ok := f.Val.(SecretValue); ok && f.Type == AnyType {
+		*f = String(f.Key, r.replacement(fmt.Sprint(s.val)))
+		return
+	}
+	switch f.Type {
+	case StringType:
+		if s, ok := r.scan(f.Str); ok {
+			*f = String(f.Key, s)
+		}
+	case ErrorType, StringerType, ObjectMarshalerType, AnyType:
+		v, ok := r.redactValue(f.Val)
+		if !ok {
+			return
+		}
+		if s, isString := v.(string); isString {
+			*f = String(f.Key, s)
+			return
+		}
+		f.Val = v
+	}
+}
+
+// redactValue returns v with its sensitive parts redacted and true, or false if it has none.
+// Strings, byte slices, errors and Stringers are scanned for the patterns, and the values
+// of maps with string keys and of ObjectMarshalers are redacted by key as fields are.
+func (r *redactor) redactValue(v any) (any, bool) {
+	if len(r.keys) == 0 && len(r.globs) == 0 && len(r.patterns) == 0 {
+		return v, false
+	}
+	switch v := v.(type) {
+	case nil, SecretValue:
+		return v, false
+	case string:
+		return r.scanValue(v)
+	case []byte:
+		return r.scanValue(string(v))
+	case ObjectMarshaler:
+		return redactedObject{m: v, r: r}, true
+	case error:
+		if isNilPointer(v) {
+			return v, false
+		}
+		return r.scanValue(v.Error())
+	case fmt.Stringer:
+		if isNilPointer(v) {
+			return v, false
+		}
+		return r.scanValue(v.String())
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+		return r.redactMap(rv)
+	}
+	return v, false
+}
+
+// redactMap returns a copy of the map m with string keys, its sensitive values redacted,
+// and true, or false if it has none.
+func (r *redactor) redactMap(m reflect.Value) (any, bool) {
+	var out map[string]any
+	iter := m.MapRange()
+	for iter.Next() {
+		key := iter.Key().String()
+		val := iter.Value().Interface()
+		var redacted any
+		changed := r.matchKey(key)
+		if changed {
+			redacted = r.replacement(fmt.Sprint(val))
+		} else {
+			redacted, changed = r.redactValue(val)
+		}
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]any, m.Len())
+			for _, k := range m.MapKeys() {
+				out[k.String()] = m.MapIndex(k).Interface()
+			}
+		}
+		out[key] = redacted
+	}
+	if out == nil {
+		return m.Interface(), false
+	}
+	return out, true
+}
+
+// scan returns s with the pattern matches replaced and true, or false if there is none.
+func (r *redactor) scan(s string) (string, bool) {
+	if len(r.patterns) == 0 {
+		return s, false
+	}
+	redacted := r.redactString(s)
+	return redacted, redacted != s
+}
+
+// scanValue is scan for redactValue.
+func (r *redactor) scanValue(s string) (any, bool) {
+	if redacted, ok := r.scan(s); ok {
+		return redacted, true
+	}
+	return nil, false
+}
+
+// isNilPointer reports whether v is a nil pointer, whose methods may not be callable.
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// redactedObject is an ObjectMarshaler whose values are redacted as the fields of an entry.
+type redactedObject struct {
+	m ObjectMarshaler
+	r *redactor
+}
+
+// MarshalLogObject implements ObjectMarshaler.
+func (o redactedObject) MarshalLogObject(enc ObjectEncoder) error {
+	return o.m.MarshalLogObject(redactingObjectEncoder{ObjectEncoder: enc, r: o.r})
+}
+
+// redactingObjectEncoder redacts the values added to an ObjectEncoder.
+type redactingObjectEncoder struct {
+	ObjectEncoder
+	r *redactor
+}
+
+// redactKey adds the replacement of val if key is sensitive, and reports whether it did.
+func (e redactingObjectEncoder) redactKey(key string, val any) bool {
+	if !e.r.matchKey(key) {
+		return false
+	}
+	e.ObjectEncoder.AddString(key, e.r.replacement(fmt.Sprint(val)))
+	return true
+}
+
+func (e redactingObjectEncoder) AddString(key, val string) {
+	if e.redactKey(key, val) {
+		return
+	}
+	if s, ok := e.r.scan(val); ok {
+		val = s
+	}
+	e.ObjectEncoder.AddString(key, val)
+}
+
+func (e redactingObjectEncoder) AddInt(key string, val int) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddInt(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddInt64(key string, val int64) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddInt64(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddUint64(key string, val uint64) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddUint64(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddFloat64(key string, val float64) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddFloat64(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddBool(key string, val bool) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddBool(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddDuration(key string, val time.Duration) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddDuration(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddTime(key string, val time.Time) {
+	if !e.redactKey(key, val) {
+		e.ObjectEncoder.AddTime(key, val)
+	}
+}
+
+func (e redactingObjectEncoder) AddAny(key string, val any) {
+	if e.redactKey(key, val) {
+		return
+	}
+	if v, ok := e.r.redactValue(val); ok {
+		val = v
+	}
+	e.ObjectEncoder.AddAny(key, val)
+}
+
+func (e redactingObjectEncoder) AddObject(key string, val ObjectMarshaler) error {
+	if e.redactKey(key, val) {
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, redactedObject{m: val, r: e.r})
+}
+
+func (e redactingObjectEncoder) AddArray(key string, val ArrayMarshaler) error {
+	if e.redactKey(key, val) {
+		return nil
+	}
+	return e.ObjectEncoder.AddArray(key, val)
+}
+
+// matchKey reports whether values under key must be redacted.
+func (r *redactor) matchKey(key string) bool {
+	if len(r.keys) == 0 && len(r.globs) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	if _, ok := r.keys[key]; ok {
+		return true
+	}
+	for _, g := range r.globs {
+		if ok, _ := path.Match(g, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces every pattern match in s.
+func (r *redactor) redactString(s string) string {
+	for _, re := range r.patterns {
+		if !re.MatchString(s) {
+			continue
+		}
+		s = re.ReplaceAllStringFunc(s, r.replacement)
+	}
+	return s
+}
+
+// replacement returns what a sensitive value s is replaced with.
+func (r *redactor) replacement(s string) string {
+	if r.mode != RedactHash {
+		return redactedMask
+	}
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// fieldString returns the value of f as a string, for hashing.
+func fieldString(f Field) string {
+	switch f.Type {
+	case StringType:
+		return f.Str
+	case Int64Type, Uint64Type, Float64Type, BoolType, DurationType, TimeType:
+		b := appendField(nil, f, nil)
+		return string(b)
+	case ErrorType:
+		return f.Val.(error).Error()
+	case StringerType:
+		return f.Val.(fmt.Stringer).String()
+	}
+	if s, ok := f.Val.(SecretValue); ok {
+		return fmt.Sprint(s.val)
+	}
+	return fmt.Sprint(f.Val)
+}
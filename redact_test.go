@@ -0,0 +1,110 @@
+package golog_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	require.NoError(golog.LoadConfig("./testdata/redact.yml"))
+	configs := golog.GetConfigs()
+	require.Equal([]string{"password", "*token*"}, configs.Default.Redact.Keys)
+	require.Equal(golog.RedactMask, configs.Default.Redact.Mode)
+
+	var buf buffer.Buffer
+	cfg := configs.Default
+	cfg.Handler = golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf}
+	log, err := golog.NewLoggerByConfig("redact", cfg)
+	require.NoError(err)
+
+	log.WithValues("Password", "hunter2", "user", "john").Info("mail sent to john@example.com",
+		"X-Auth-Token", 12345,
+		"header", "Bearer abc.def-ghi",
+		"err", errors.New("no user ssn-123"),
+		golog.String("note", "nothing to hide"),
+		"pin", golog.Secret(1234),
+	)
+	require.True(validJSON(buf.String()))
+	require.Equal(`{"level":"info","message":"mail sent to ***","Password":"***","user":"john","X-Auth-Token":"***",`+
+		`"header":"***","err":"no user ***","note":"nothing to hide","pin":"***"}`+"\n", buf.String())
+
+	cfg.Encoding = golog.TextEncoding
+	cfg.TextEncoder = golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true}
+	log, err = golog.NewLoggerByConfig("redact", cfg)
+	require.NoError(err)
+	buf.Reset()
+	log.Info("login by jane@example.org", "password", "secret", "access_token", "t0k3n")
+	require.Equal("INFO login by *** password=*** access_token=***\n", buf.String())
+}
+
+func TestRedact_Hash(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("redact", golog.Config{
+		Encoding:    golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{DisableTimestamp: true},
+		Handler:     golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+		Redact:      golog.RedactConfig{Keys: []string{"password"}, Mode: golog.RedactHash},
+	})
+	require.NoError(err)
+	log.Info("hash", "password", "hunter2", "secret", golog.Secret("hunter2"))
+	require.Equal(`{"level":"info","message":"hash","password":"sha256:f52fbd32b2b3b86f","secret":"sha256:f52fbd32b2b3b86f"}`+"\n", buf.String())
+}
+
+func TestRedact_Secret(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+	golog.Info("secret", "pin", golog.Secret(1234))
+	require.Equal("INFO secret pin=***\n", buf.String())
+	require.Equal("***", golog.Secret("x").String())
+}
+
+type redactStringer string
+
+func (s redactStringer) String() string {
+	return string(s)
+}
+
+func TestRedact_Nested(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log := newTestLogger(t, "redact", &buf, golog.Config{
+		Redact: golog.RedactConfig{Keys: []string{"password"}, Presets: []string{"email"}},
+	})
+	user := map[string]any{"name": "john", "password": "hunter2", "contact": map[string]string{"mail": "john@example.com"}}
+	login := golog.ObjectMarshalerFunc(func(enc golog.ObjectEncoder) error {
+		enc.AddString("user", "jane@example.org")
+		enc.AddInt("password", 1234)
+		return enc.AddObject("meta", golog.ObjectMarshalerFunc(func(enc golog.ObjectEncoder) error {
+			enc.AddAny("password", "x")
+			return nil
+		}))
+	})
+	log.Info("nested", "user", user, golog.Object("login", login),
+		"body", []byte("from bob@example.net"), "to", redactStringer("ann@example.com"))
+	require.Equal(`{"level":"info","message":"nested","user":{"contact":{"mail":"***"},"name":"john","password":"***"},`+
+		`"login":{"user":"***","password":"***","meta":{"password":"***"}},"body":"from ***","to":"***"}`+"\n", buf.String())
+	require.Equal("hunter2", user["password"])
+}
+
+func TestRedact_InvalidConfig(t *testing.T) {
+	require := require.New(t)
+	for _, rc := range []golog.RedactConfig{
+		{Patterns: []string{"("}},
+		{Presets: []string{"unknown"}},
+		{Keys: []string{"[a"}},
+		{Mode: "scramble"},
+	} {
+		_, err := golog.NewLoggerByConfig("redact", golog.Config{Redact: rc})
+		require.Error(err)
+	}
+}
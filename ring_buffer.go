@@ -0,0 +1,187 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRingBufferSize is the number of entries kept if RingBufferConfig.Size is not set.
+const defaultRingBufferSize = 1000
+
+// RingBufferConfig is the configuration for RingBuffer.
+type RingBufferConfig struct {
+	// Size is the maximum number of entries kept, 1000 if not set.
+	Size int `json:"size" yaml:"size"`
+	// MaxBytes is the maximum size of the encoded entries kept. Unlimited if not set.
+	MaxBytes int `json:"maxBytes" yaml:"maxBytes"`
+	// Level is the least severe level kept, DEBUG if not set, even if filtered out of the
+	// writer of the logger.
+	Level Level `json:"level" yaml:"level"`
+	// Structured keeps the fields of the entries, in addition to their encoding.
+	Structured bool `json:"structured" yaml:"structured"`
+}
+
+// RingEntry is an entry kept by a RingBuffer.
+type RingEntry struct {
+	Time    time.Time
+	Level   Level
+	Module  string
+	Message string
+	// Data is the entry as encoded by the logger.
+	Data []byte
+	// Fields holds the fields of the entry, groups holding their fields in Val, if RingBufferConfig.Structured is set.
+	Fields []Field
+}
+
+// RingBuffer is an EntryHandler keeping the last entries of the loggers it is set on in memory,
+// for diagnostics. The buffers of the entries are reused once they are dropped.
+type RingBuffer struct {
+	cfg     RingBufferConfig
+	mu      sync.Mutex
+	entries []RingEntry
+	// head is the index of the oldest entry, n the number of entries.
+	head  int
+	n     int
+	bytes int
+}
+
+var _ EntryHandler = (*RingBuffer)(nil)
+
+// NewRingBuffer creates a new RingBuffer, to be set in Config.EntryHandlers.
+func NewRingBuffer(cfg RingBufferConfig) *RingBuffer {
+	if cfg.Size <= 0 {
+		cfg.Size = defaultRingBufferSize
+	}
+	if cfg.Level == 0 {
+		cfg.Level = DEBUG
+	}
+	return &RingBuffer{cfg: cfg, entries: make([]RingEntry, cfg.Size)}
+}
+
+// Enabled implements EntryHandler.
+func (rb *RingBuffer) Enabled(level Level) bool {
+	return level <= rb.cfg.Level
+}
+
+// Handle implements EntryHandler.
+func (rb *RingBuffer) Handle(e *Entry) error {
+	var fields []Field
+	if rb.cfg.Structured {
+		fields = e.NestedFields()
+	}
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	if rb.n == len(rb.entries) {
+		rb.drop()
+	}
+	for rb.cfg.MaxBytes > 0 && rb.n > 0 && rb.bytes+len(e.Data) > rb.cfg.MaxBytes {
+		rb.drop()
+	}
+	re := &rb.entries[(rb.head+rb.n)%len(rb.entries)]
+	re.Time = e.Time
+	re.Level = e.Level
+	re.Module = e.Module
+	re.Message = e.Message
+	re.Data = append(re.Data[:0], e.Data...)
+	re.Fields = fields
+	rb.n++
+	rb.bytes += len(re.Data)
+	return nil
+}
+
+// drop drops the oldest entry.
+func (rb *RingBuffer) drop() {
+	re := &rb.entries[rb.head]
+	rb.bytes -= len(re.Data)
+	re.Fields = nil
+	rb.head = (rb.head + 1) % len(rb.entries)
+	rb.n--
+}
+
+// Len returns the number of entries kept.
+func (rb *RingBuffer) Len() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.n
+}
+
+// Snapshot returns a copy of the entries kept, oldest first.
+func (rb *RingBuffer) Snapshot() []RingEntry {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	entries := make([]RingEntry, 0, rb.n)
+	rb.eachLocked(func(re *RingEntry) {
+		e := *re
+		e.Data = bytes.Clone(e.Data)
+		entries = append(entries, e)
+	})
+	return entries
+}
+
+// each calls fn with the entries kept, oldest first. The entries must not be retained.
+func (rb *RingBuffer) each(fn func(*RingEntry)) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rb.eachLocked(fn)
+}
+
+func (rb *RingBuffer) eachLocked(fn func(*RingEntry)) {
+	for i := 0; i < rb.n; i++ {
+		fn(&rb.entries[(rb.head+i)%len(rb.entries)])
+	}
+}
+
+// WriteTo writes the encoded entries kept to w, oldest first.
+func (rb *RingBuffer) WriteTo(w io.Writer) (int64, error) {
+	return writeRingEntries(w, rb.Snapshot())
+}
+
+// ServeHTTP writes the encoded entries kept, oldest first. The query parameter level keeps
+// the entries at or above a level, and n the last n entries.
+func (rb *RingBuffer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	entries := rb.Snapshot()
+	q := r.URL.Query()
+	if s := q.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n := 0
+		for _, e := range entries {
+			if e.Level <= level {
+				entries[n] = e
+				n++
+			}
+		}
+		entries = entries[:n]
+	}
+	if s := q.Get("n"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid n: "+s, http.StatusBadRequest)
+			return
+		}
+		if n < len(entries) {
+			entries = entries[len(entries)-n:]
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = writeRingEntries(w, entries)
+}
+
+func writeRingEntries(w io.Writer, entries []RingEntry) (int64, error) {
+	var total int64
+	for _, e := range entries {
+		n, err := w.Write(e.Data)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
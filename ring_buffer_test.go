@@ -0,0 +1,92 @@
+package golog_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBuffer(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	rb := golog.NewRingBuffer(golog.RingBufferConfig{Size: 3, Structured: true})
+	log := newTestLogger(t, "ring", &buf, golog.Config{EntryHandlers: []golog.EntryHandler{rb}})
+
+	log.Debug("one")
+	log.Info("two", "a", 1)
+	log.Debug("three")
+	log.Warn("four")
+	require.Equal(`{"level":"info","message":"two","a":1}`+"\n"+`{"level":"warning","message":"four"}`+"\n", buf.String())
+
+	entries := rb.Snapshot()
+	require.Len(entries, 3)
+	require.Equal("two", entries[0].Message)
+	require.Equal(golog.INFO, entries[0].Level)
+	require.Equal("ring", entries[0].Module)
+	require.Equal([]golog.Field{golog.Any("a", 1)}, entries[0].Fields)
+	require.Equal(`{"level":"debug","message":"three"}`+"\n", string(entries[1].Data))
+
+	var out bytes.Buffer
+	n, err := rb.WriteTo(&out)
+	require.NoError(err)
+	require.Equal(int64(out.Len()), n)
+	require.Equal(`{"level":"info","message":"two","a":1}`+"\n"+
+		`{"level":"debug","message":"three"}`+"\n"+
+		`{"level":"warning","message":"four"}`+"\n", out.String())
+}
+
+func TestRingBuffer_MaxBytes(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	line := len(`{"level":"info","message":"0"}` + "\n")
+	rb := golog.NewRingBuffer(golog.RingBufferConfig{MaxBytes: 2 * line, Level: golog.INFO})
+	log := newTestLogger(t, "ring", &buf, golog.Config{EntryHandlers: []golog.EntryHandler{rb}})
+
+	for _, msg := range []string{"0", "1", "2"} {
+		log.Info(msg)
+	}
+	log.Debug("3")
+	entries := rb.Snapshot()
+	require.Equal(2, rb.Len())
+	require.Equal("1", entries[0].Message)
+	require.Equal("2", entries[1].Message)
+	require.Nil(entries[0].Fields)
+}
+
+func TestRingBuffer_ServeHTTP(t *testing.T) {
+	require := require.New(t)
+	var buf bytes.Buffer
+	rb := golog.NewRingBuffer(golog.RingBufferConfig{})
+	log := newTestLogger(t, "ring", &buf, golog.Config{EntryHandlers: []golog.EntryHandler{rb}})
+	log.Debug("one")
+	log.Error("two")
+	log.Warn("three")
+	log.Info("four")
+
+	srv := httptest.NewServer(rb)
+	defer srv.Close()
+	get := func(query string) (int, string) {
+		resp, err := http.Get(srv.URL + "?" + query)
+		require.NoError(err)
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(err)
+		return resp.StatusCode, string(b)
+	}
+
+	status, body := get("")
+	require.Equal(http.StatusOK, status)
+	require.Equal(4, strings.Count(body, "\n"))
+	_, body = get("level=warning&n=1")
+	require.Equal(`{"level":"warning","message":"three"}`+"\n", body)
+	status, _ = get("level=loud")
+	require.Equal(http.StatusBadRequest, status)
+	status, _ = get("n=-1")
+	require.Equal(http.StatusBadRequest, status)
+}
@@ -15,7 +15,6 @@ import (
 )
 
 var (
-	currentTime             = time.Now
 	defaultBackupTimeFormat = "20060102"
 
 	_ io.Writer = (*RotateFile)(nil)
@@ -40,6 +39,12 @@ type RotateFileConfig struct {
 
 	// Async determines if the log write should be async
 	Async bool `json:"async" yaml:"async"`
+
+	// FlushInterval is the interval at which buffered data is flushed to the file in Async mode.
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval"`
+
+	// Clock tells the time deciding the rotations and naming the backups, SystemClock if not set.
+	Clock Clock `json:"-" yaml:"-"`
 }
 
 // RotateFile rotates log files based on time.
@@ -51,6 +56,7 @@ type RotateFile struct {
 	mu                sync.Mutex
 	workerOnce        sync.Once
 	workerCh          chan bool
+	stop              chan struct{}
 }
 
 // NewRotateFile creates a new RotateFile.
@@ -58,12 +64,20 @@ func NewRotateFile(cfg RotateFileConfig) (*RotateFile, error) {
 	if cfg.MaxBackups < 0 {
 		return nil, fmt.Errorf("maxbackups cannot be negative")
 	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
 	f := &RotateFile{
 		cfg: cfg,
 	}
 	if err := f.open(); err != nil {
 		return nil, err
 	}
+	if cfg.Async && cfg.FlushInterval > 0 {
+		f.stop = make(chan struct{})
+		go flushEvery(cfg.FlushInterval, f.stop, f.Flush)
+	}
+	registerWriter(f)
 	return f, nil
 }
 
@@ -93,7 +107,7 @@ func (f *RotateFile) backupTimeFormat() string {
 
 func (f *RotateFile) open() error {
 	var err error
-	t := currentTime()
+	t := f.cfg.Clock.Now()
 	if !f.cfg.LocalTime {
 		t = t.UTC()
 	}
@@ -107,10 +121,38 @@ func (f *RotateFile) open() error {
 	return nil
 }
 
+// Flush writes any buffered data to the current logfile.
+func (f *RotateFile) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.bufferWriter.Flush()
+}
+
+// Sync flushes any buffered data and commits the current logfile to stable storage.
+func (f *RotateFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	if err := f.bufferWriter.Flush(); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
 // Close implements io.Closer, and closes the current logfile.
 func (f *RotateFile) Close() error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+	unregisterWriter(f)
+	if f.stop != nil {
+		close(f.stop)
+		f.stop = nil
+	}
 	err := f.close()
 	if f.workerCh != nil {
 		close(f.workerCh)
@@ -137,7 +179,7 @@ func (f *RotateFile) reopenIfNeeded() (bool, error) {
 	if f.file == nil {
 		return false, f.open()
 	}
-	t := currentTime()
+	t := f.cfg.Clock.Now()
 	if !f.cfg.LocalTime {
 		t = t.UTC()
 	}
@@ -282,7 +324,7 @@ func (f *RotateFile) backupName() string {
 	filename := filepath.Base(f.Filename())
 	ext := filepath.Ext(filename)
 	prefix := filename[:len(filename)-len(ext)]
-	t := currentTime()
+	t := f.cfg.Clock.Now()
 	if !f.cfg.LocalTime {
 		t = t.UTC()
 	}
@@ -18,12 +18,12 @@ func fakeTime() time.Time {
 
 func TestNewFile(t *testing.T) {
 	require := require.New(t)
-	currentTime = fakeTime
 	dir := makeTempDir("TestNewFile", t)
 	defer os.RemoveAll(dir)
 	filename := logFile(dir)
 	l, err := NewRotateFile(RotateFileConfig{
 		Filename: filename,
+		Clock:    ClockFunc(fakeTime),
 	})
 	require.NoError(err)
 	defer l.Close()
@@ -51,7 +51,6 @@ func TestNewFile(t *testing.T) {
 
 func TestRotate(t *testing.T) {
 	require := require.New(t)
-	currentTime = fakeTime
 	dir := makeTempDir("TestRotate", t)
 	defer os.RemoveAll(dir)
 
@@ -60,6 +59,7 @@ func TestRotate(t *testing.T) {
 	l, err := NewRotateFile(RotateFileConfig{
 		Filename:   filename,
 		MaxBackups: 1,
+		Clock:      ClockFunc(fakeTime),
 	})
 	require.NoError(err)
 	defer l.Close()
@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// syncCloser is a writer opened by golog.
+type syncCloser interface {
+	Sync() error
+	Close() error
+}
+
+var writers struct {
+	mu   sync.Mutex
+	list []syncCloser
+}
+
+func registerWriter(w syncCloser) {
+	writers.mu.Lock()
+	writers.list = append(writers.list, w)
+	writers.mu.Unlock()
+}
+
+func unregisterWriter(w syncCloser) {
+	writers.mu.Lock()
+	writers.list = slices.DeleteFunc(writers.list, func(v syncCloser) bool { return v == w })
+	writers.mu.Unlock()
+}
+
+// Sync flushes the buffered data of all the files opened by golog and commits them to stable storage.
+func Sync() error {
+	writers.mu.Lock()
+	list := slices.Clone(writers.list)
+	writers.mu.Unlock()
+	var errs []error
+	for _, w := range list {
+		errs = append(errs, w.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes all the files opened by golog.
+// Loggers writing to them must not be used afterwards.
+func Close() error {
+	writers.mu.Lock()
+	list := writers.list
+	writers.list = nil
+	writers.mu.Unlock()
+	var errs []error
+	for _, w := range list {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// FlushOnSignal closes the files opened by golog when the process receives one of sigs,
+// os.Interrupt and SIGTERM if none is given, then raises the signal again so that the
+// process terminates as it would have. The returned function stops the handling.
+func FlushOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		select {
+		case sig := <-ch:
+			signal.Stop(ch)
+			if err := Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "golog: failed to close log files: %v\n", err)
+			}
+			if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+				return
+			}
+			os.Exit(1)
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// flushEvery calls flush at every interval until stop is closed.
+func flushEvery(interval time.Duration, stop <-chan struct{}, flush func() error) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if err := flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "golog: failed to flush log: %v\n", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
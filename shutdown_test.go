@@ -0,0 +1,77 @@
+package golog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestSyncAndClose(t *testing.T) {
+	require := require.New(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.log")
+	rotate := filepath.Join(dir, "rotate.log")
+	fileLog := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{Type: golog.HandlerTypeFile, File: golog.FileConfig{Path: file}}})
+	rotateLog := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{
+		Type:       golog.HandlerTypeRotateFile,
+		RotateFile: golog.RotateFileConfig{Filename: rotate, Async: true},
+	}})
+
+	fileLog.Info("one")
+	rotateLog.Info("two")
+	require.Empty(readFile(t, file))
+	require.Empty(readFile(t, rotate))
+
+	require.NoError(golog.Sync())
+	require.Equal(`{"level":"info","message":"one"}`+"\n", readFile(t, file))
+	require.Equal(`{"level":"info","message":"two"}`+"\n", readFile(t, rotate))
+
+	fileLog.Info("three")
+	require.NoError(golog.Close())
+	require.Contains(readFile(t, file), "three")
+	require.NoError(golog.Sync())
+}
+
+func TestLog_Close(t *testing.T) {
+	require := require.New(t)
+	path := filepath.Join(t.TempDir(), "file.log")
+	log := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{Type: golog.HandlerTypeFile, File: golog.FileConfig{Path: path}}})
+	log.Info("closed")
+	require.NoError(log.Close())
+	require.Equal(`{"level":"info","message":"closed"}`+"\n", readFile(t, path))
+}
+
+func TestFlushInterval(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.log")
+	rotate := filepath.Join(dir, "rotate.log")
+	fileLog := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{
+		Type: golog.HandlerTypeFile,
+		File: golog.FileConfig{Path: file, FlushInterval: 10 * time.Millisecond},
+	}})
+	defer fileLog.Close()
+	rotateLog := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{
+		Type:       golog.HandlerTypeRotateFile,
+		RotateFile: golog.RotateFileConfig{Filename: rotate, Async: true, FlushInterval: 10 * time.Millisecond},
+	}})
+	defer rotateLog.Close()
+
+	fileLog.Info("ticked")
+	rotateLog.Info("ticked")
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(file)
+		r, _ := os.ReadFile(rotate)
+		return len(b) > 0 && len(r) > 0
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package golog_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlushOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.log")
+	log := newTestLogger(t, "shutdown", nil, golog.Config{Handler: golog.HandlerConfig{Type: golog.HandlerTypeFile, File: golog.FileConfig{Path: path}}})
+	// SIGWINCH is ignored by default, so raising it again does not end the test.
+	stop := golog.FlushOnSignal(syscall.SIGWINCH)
+	defer stop()
+
+	log.Info("signaled")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGWINCH))
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(path)
+		return len(b) > 0
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, `{"level":"info","message":"signaled"}`+"\n", readFile(t, path))
+}
@@ -0,0 +1,251 @@
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+)
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.Pinger             = (*conn)(nil)
+	_ driver.SessionResetter    = (*conn)(nil)
+	_ driver.Validator          = (*conn)(nil)
+	_ driver.NamedValueChecker  = (*conn)(nil)
+
+	_ driver.Stmt              = (*stmt)(nil)
+	_ driver.StmtExecContext   = (*stmt)(nil)
+	_ driver.StmtQueryContext  = (*stmt)(nil)
+	_ driver.NamedValueChecker = (*stmt)(nil)
+
+	_ driver.ColumnConverter = (*convertingStmt)(nil) //nolint:staticcheck // Forwarded for older drivers.
+)
+
+// conn wraps a driver.Conn, implementing the optional interfaces by falling back
+// to what the wrapped connection supports, as database/sql does.
+type conn struct {
+	driver.Conn
+	l *logger
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := c.l.cfg.Clock.Now()
+	var s driver.Stmt
+	var err error
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = pc.PrepareContext(ctx, query)
+	} else if err = ctx.Err(); err == nil {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		c.l.log(ctx, "prepare", query, nil, start, -1, err)
+		return nil, err
+	}
+	ws := &stmt{Stmt: s, query: query, l: c.l}
+	if _, ok := s.(driver.ColumnConverter); ok { //nolint:staticcheck // Forwarded for older drivers.
+		return &convertingStmt{ws}, nil
+	}
+	return ws, nil
+}
+
+//nolint:staticcheck // Begin is part of driver.Conn.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.Conn.Begin()
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := c.l.cfg.Clock.Now()
+	var tx driver.Tx
+	var err error
+	if bt, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = bt.BeginTx(ctx, opts)
+	} else if opts.Isolation != 0 {
+		err = errors.New("sqllog: driver does not support non-default isolation level")
+	} else if opts.ReadOnly {
+		err = errors.New("sqllog: driver does not support read-only transactions")
+	} else if err = ctx.Err(); err == nil {
+		tx, err = c.Begin()
+	}
+	if err != nil {
+		c.l.log(ctx, "begin", "", nil, start, -1, err)
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := c.l.cfg.Clock.Now()
+	var res driver.Result
+	var err error
+	switch ec := c.Conn.(type) {
+	case driver.ExecerContext:
+		res, err = ec.ExecContext(ctx, query, args)
+	case driver.Execer: //nolint:staticcheck // Fallback for older drivers.
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = ec.Exec(query, values)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+	c.l.log(ctx, "exec", query, args, start, rowsAffected(res, err), err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := c.l.cfg.Clock.Now()
+	var rows driver.Rows
+	var err error
+	switch qc := c.Conn.(type) {
+	case driver.QueryerContext:
+		rows, err = qc.QueryContext(ctx, query, args)
+	case driver.Queryer: //nolint:staticcheck // Fallback for older drivers.
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = qc.Query(query, values)
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+	c.l.log(ctx, "query", query, args, start, -1, err)
+	return rows, err
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt wraps a driver.Stmt.
+type stmt struct {
+	driver.Stmt
+	query string
+	l     *logger
+}
+
+//nolint:staticcheck // Exec is part of driver.Stmt.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valueArgs(args))
+}
+
+//nolint:staticcheck // Query is part of driver.Stmt.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valueArgs(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := s.l.cfg.Clock.Now()
+	var res driver.Result
+	var err error
+	if ec, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			if err = ctx.Err(); err == nil {
+				res, err = s.Stmt.Exec(values) //nolint:staticcheck // Fallback for older drivers.
+			}
+		}
+	}
+	s.l.log(ctx, "exec", s.query, args, start, rowsAffected(res, err), err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := s.l.cfg.Clock.Now()
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			if err = ctx.Err(); err == nil {
+				rows, err = s.Stmt.Query(values) //nolint:staticcheck // Fallback for older drivers.
+			}
+		}
+	}
+	s.l.log(ctx, "query", s.query, args, start, -1, err)
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// convertingStmt wraps a driver.Stmt implementing driver.ColumnConverter. database/sql
+// changes how it converts arguments when a statement implements it, so only the statements
+// of drivers implementing it do.
+type convertingStmt struct {
+	*stmt
+}
+
+func (s *convertingStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.Stmt.(driver.ColumnConverter).ColumnConverter(idx) //nolint:staticcheck // Forwarded for older drivers.
+}
+
+// rowsAffected returns the number of rows affected by res, or -1.
+func rowsAffected(res driver.Result, err error) int64 {
+	if err != nil || res == nil {
+		return -1
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// namedValues returns the values of args, which the drivers without context support cannot name.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sqllog: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+func valueArgs(values []driver.Value) []driver.NamedValue {
+	args := make([]driver.NamedValue, len(values))
+	for i, v := range values {
+		args[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return args
+}
@@ -0,0 +1,149 @@
+package sqllog_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/millken/golog/gologtest"
+)
+
+// fakeDriver is a tiny in-memory driver. Queries are interpreted by their first word:
+//
+//	INSERT  affects as many rows as it has arguments
+//	SELECT  returns the arguments as a single row
+//	FAIL    fails
+//	SLOW    moves the clock by two seconds
+//	SKIP    is only supported once prepared
+//	CONVERT converts its argument to a string once prepared
+type fakeDriver struct {
+	clock *gologtest.Clock
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{clock: d.clock}, nil
+}
+
+type fakeConnector struct {
+	fakeDriver
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.Open("")
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return c.fakeDriver
+}
+
+type fakeConn struct {
+	clock *gologtest.Clock
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.HasPrefix(query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+	if strings.HasPrefix(query, "CONVERT") {
+		return &fakeConvertStmt{fakeStmt{conn: c, query: query}}, nil
+	}
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "SKIP") {
+		return nil, driver.ErrSkip
+	}
+	return c.exec(query, len(args))
+}
+
+func (c *fakeConn) exec(query string, n int) (driver.Result, error) {
+	switch strings.Fields(query)[0] {
+	case "FAIL":
+		return nil, errors.New("constraint violation")
+	case "SLOW":
+		c.clock.Add(2 * time.Second)
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "FAIL") {
+		return nil, errors.New("no such table")
+	}
+	row := make([]driver.Value, len(args))
+	for i, arg := range args {
+		row[i] = arg.Value
+	}
+	return &fakeRows{row: row}, nil
+}
+
+// fakeStmt only supports the methods without context.
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.exec(s.query, len(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{row: args}, nil
+}
+
+// fakeConvertStmt converts its single argument with a driver.ColumnConverter.
+type fakeConvertStmt struct {
+	fakeStmt
+}
+
+func (s *fakeConvertStmt) NumInput() int { return 1 }
+
+func (s *fakeConvertStmt) ColumnConverter(int) driver.ValueConverter {
+	return fakeConverter{}
+}
+
+type fakeConverter struct{}
+
+func (fakeConverter) ConvertValue(v any) (driver.Value, error) {
+	return fmt.Sprintf("converted %v", v), nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	cols := make([]string, len(r.row))
+	for i := range cols {
+		cols[i] = "c"
+	}
+	return cols
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.row)
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package sqllog wraps database/sql drivers to log their queries with golog.
+//
+//	connector, err := pq.NewConnector(dsn)
+//	...
+//	db := sql.OpenDB(sqllog.WrapConnector(connector, sqllog.Config{SlowThreshold: time.Second}))
+//
+// Or, for drivers registered by name:
+//
+//	sql.Register("logged-postgres", sqllog.Wrap(&pq.Driver{}, sqllog.Config{}))
+package sqllog
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/millken/golog"
+)
+
+// Field names of the entries.
+const (
+	QueryFieldName        = "query"
+	ArgsFieldName         = "args"
+	DurationFieldName     = "duration"
+	RowsAffectedFieldName = "rowsAffected"
+)
+
+// Config is the configuration of the wrapped drivers.
+type Config struct {
+	// Logger logs the queries. The logger carried by the context of the query, or the
+	// global logger, is used if not set.
+	Logger golog.Logger
+	// Level is the level of the queries, DEBUG if not set.
+	Level golog.Level
+	// SlowThreshold is the duration from which queries are logged at SlowLevel. Disabled if not set.
+	SlowThreshold time.Duration
+	// SlowLevel is the level of the slow queries, WARNING if not set.
+	SlowLevel golog.Level
+	// ErrorLevel is the level of the failed queries, ERROR if not set.
+	ErrorLevel golog.Level
+	// SampleEvery logs one in SampleEvery of the queries neither slow nor failed. All are logged if not set.
+	SampleEvery int
+	// LogArgs logs the arguments of the queries, as returned by Redact if set.
+	LogArgs bool
+	// Redact returns the value logged for an argument of query, e.g. SecretArg.
+	Redact func(query string, arg driver.NamedValue) any
+	// Clock tells the time of the queries, golog.SystemClock if not set.
+	Clock golog.Clock
+}
+
+// SecretArg redacts all the arguments, to be set as Config.Redact.
+func SecretArg(_ string, arg driver.NamedValue) any {
+	return golog.Secret(arg.Value)
+}
+
+// logger logs the operations of the wrapped drivers.
+type logger struct {
+	cfg   Config
+	count atomic.Uint64
+}
+
+func newLogger(cfg Config) *logger {
+	if cfg.Level == 0 {
+		cfg.Level = golog.DEBUG
+	}
+	if cfg.SlowLevel == 0 {
+		cfg.SlowLevel = golog.WARNING
+	}
+	if cfg.ErrorLevel == 0 {
+		cfg.ErrorLevel = golog.ERROR
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = golog.SystemClock
+	}
+	return &logger{cfg: cfg}
+}
+
+// log logs the operation op on query, started at start. rows is the number of rows
+// affected, or negative if unknown.
+func (l *logger) log(ctx context.Context, op, query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+	d := l.cfg.Clock.Now().Sub(start)
+	level := l.cfg.Level
+	switch {
+	case err != nil:
+		level = l.cfg.ErrorLevel
+	case l.cfg.SlowThreshold > 0 && d >= l.cfg.SlowThreshold:
+		level = l.cfg.SlowLevel
+	case l.cfg.SampleEvery > 1 && (l.count.Add(1)-1)%uint64(l.cfg.SampleEvery) != 0:
+		return
+	}
+
+	fields := make([]golog.Field, 0, 5)
+	fields = append(fields, golog.String(QueryFieldName, query))
+	if l.cfg.LogArgs && len(args) > 0 {
+		values := make([]any, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+			if l.cfg.Redact != nil {
+				values[i] = l.cfg.Redact(query, arg)
+			}
+		}
+		fields = append(fields, golog.Any(ArgsFieldName, values))
+	}
+	fields = append(fields, golog.Duration(DurationFieldName, d))
+	if rows >= 0 {
+		fields = append(fields, golog.Int64(RowsAffectedFieldName, rows))
+	}
+	if err != nil {
+		fields = append(fields, golog.Err(err))
+	}
+	logger := l.cfg.Logger
+	if logger == nil {
+		logger = golog.LoggerFromContext(ctx)
+	}
+	golog.LogFieldsTo(logger, level, op, fields...)
+}
+
+// Wrap returns a driver logging the queries of d.
+func Wrap(d driver.Driver, cfg Config) driver.Driver {
+	return wrapDriver(d, newLogger(cfg))
+}
+
+func wrapDriver(d driver.Driver, l *logger) driver.Driver {
+	if dc, ok := d.(driver.DriverContext); ok {
+		return &contextDriver{wrappedDriver: wrappedDriver{d: d, l: l}, dc: dc}
+	}
+	return &wrappedDriver{d: d, l: l}
+}
+
+type wrappedDriver struct {
+	d driver.Driver
+	l *logger
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, l: d.l}, nil
+}
+
+// contextDriver is a wrapped driver implementing driver.DriverContext.
+type contextDriver struct {
+	wrappedDriver
+	dc driver.DriverContext
+}
+
+func (d *contextDriver) OpenConnector(name string) (driver.Connector, error) {
+	c, err := d.dc.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{c: c, d: d, l: d.l}, nil
+}
+
+// WrapConnector returns a connector logging the queries of c, to be opened with sql.OpenDB.
+func WrapConnector(c driver.Connector, cfg Config) driver.Connector {
+	l := newLogger(cfg)
+	return &connector{c: c, d: wrapDriver(c.Driver(), l), l: l}
+}
+
+type connector struct {
+	c driver.Connector
+	d driver.Driver
+	l *logger
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, l: c.l}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.d
+}
+
+// Close closes the wrapped connector if it is an io.Closer, as sql.DB.Close does.
+func (c *connector) Close() error {
+	if cl, ok := c.c.(io.Closer); ok {
+		return cl.Close()
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package sqllog_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/millken/golog/sqllog"
+	"github.com/stretchr/testify/require"
+)
+
+func openDB(t *testing.T, cfg sqllog.Config) (*sql.DB, *gologtest.Observer) {
+	t.Helper()
+	log, obs := gologtest.NewLogger(t, "sql")
+	if cfg.Logger == nil {
+		cfg.Logger = log
+	}
+	clock := gologtest.NewClock(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	cfg.Clock = clock
+	db := sql.OpenDB(sqllog.WrapConnector(fakeConnector{fakeDriver{clock: clock}}, cfg))
+	t.Cleanup(func() { db.Close() })
+	return db, obs
+}
+
+func TestWrapConnector(t *testing.T) {
+	require := require.New(t)
+	db, obs := openDB(t, sqllog.Config{LogArgs: true, SlowThreshold: time.Second})
+
+	res, err := db.Exec("INSERT INTO t VALUES (?, ?)", 1, "a")
+	require.NoError(err)
+	n, err := res.RowsAffected()
+	require.NoError(err)
+	require.Equal(int64(2), n)
+	var v string
+	require.NoError(db.QueryRow("SELECT ?", "x").Scan(&v))
+	require.Equal("x", v)
+	_, err = db.Exec("FAIL")
+	require.Error(err)
+	_, err = db.Exec("SLOW")
+	require.NoError(err)
+
+	entries := obs.All()
+	require.Equal([]string{"exec", "query", "exec", "exec"}, entries.Messages())
+	require.Equal(golog.DEBUG, entries[0].Level)
+	require.Equal(map[string]any{
+		"query":        "INSERT INTO t VALUES (?, ?)",
+		"args":         []any{int64(1), "a"},
+		"duration":     time.Duration(0),
+		"rowsAffected": int64(2),
+	}, entries[0].FieldMap())
+	require.Equal([]any{"x"}, entries[1].FieldMap()["args"])
+	require.Equal(golog.ERROR, entries[2].Level)
+	require.EqualError(entries[2].FieldMap()["error"].(error), "constraint violation")
+	require.Equal(golog.WARNING, entries[3].Level)
+	require.Equal(2*time.Second, entries[3].FieldMap()["duration"])
+}
+
+func TestWrapConnector_Prepared(t *testing.T) {
+	require := require.New(t)
+	db, obs := openDB(t, sqllog.Config{})
+
+	// The connection skips these queries, so database/sql prepares them.
+	_, err := db.Exec("SKIP INSERT", 1)
+	require.NoError(err)
+	stmt, err := db.Prepare("SELECT ?")
+	require.NoError(err)
+	defer stmt.Close()
+	var v int
+	require.NoError(stmt.QueryRow(7).Scan(&v))
+	require.Equal(7, v)
+	_, err = db.Prepare("FAIL")
+	require.Error(err)
+
+	entries := obs.All()
+	require.Equal([]string{"exec", "query", "prepare"}, entries.Messages())
+	require.Equal("SKIP INSERT", entries[0].FieldMap()["query"])
+	require.Equal(int64(1), entries[0].FieldMap()["rowsAffected"])
+	_, ok := entries[0].Field("args")
+	require.False(ok)
+	require.Equal(golog.ERROR, entries[2].Level)
+}
+
+func TestWrapConnector_Redact(t *testing.T) {
+	db, obs := openDB(t, sqllog.Config{LogArgs: true, Redact: sqllog.SecretArg})
+	_, err := db.Exec("INSERT INTO users VALUES (?)", "password")
+	require.NoError(t, err)
+	require.Equal(t, []any{golog.Secret("password")}, obs.All()[0].FieldMap()["args"])
+}
+
+func TestWrapConnector_Sampling(t *testing.T) {
+	require := require.New(t)
+	db, obs := openDB(t, sqllog.Config{SampleEvery: 3})
+	for i := 0; i < 7; i++ {
+		_, err := db.Exec("INSERT")
+		require.NoError(err)
+	}
+	_, err := db.Exec("FAIL")
+	require.Error(err)
+	require.Equal([]string{"exec", "exec", "exec", "exec"}, obs.All().Messages())
+	require.Equal(1, obs.All().FilterLevel(golog.ERROR).Len())
+}
+
+func TestWrapConnector_ContextLogger(t *testing.T) {
+	log, obs := gologtest.NewLogger(t, "ctx")
+	clock := gologtest.NewClock(time.Now())
+	db := sql.OpenDB(sqllog.WrapConnector(fakeConnector{fakeDriver{clock: clock}}, sqllog.Config{}))
+	defer db.Close()
+	ctx := golog.ContextWithLogger(context.Background(), log)
+	_, err := db.ExecContext(ctx, "INSERT")
+	require.NoError(t, err)
+	gologtest.AssertLogged(t, obs, golog.DEBUG, "exec", "query", "INSERT")
+}
+
+// registered counts the drivers registered by TestWrap.
+var registered atomic.Int32
+
+func TestWrap(t *testing.T) {
+	require := require.New(t)
+	log, obs := gologtest.NewLogger(t, "sql")
+	clock := gologtest.NewClock(time.Now())
+	// Drivers cannot be unregistered: each run registers its own.
+	name := fmt.Sprintf("sqllog-fake-%d", registered.Add(1))
+	sql.Register(name, sqllog.Wrap(fakeDriver{clock: clock}, sqllog.Config{Logger: log}))
+	db, err := sql.Open(name, "")
+	require.NoError(err)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	require.NoError(err)
+	_, err = tx.Exec("INSERT", 1)
+	require.NoError(err)
+	require.NoError(tx.Commit())
+	require.NoError(db.Ping())
+	require.Equal([]string{"exec"}, obs.All().Messages())
+}
+
+func TestWrapConnector_ColumnConverter(t *testing.T) {
+	require := require.New(t)
+	db, _ := openDB(t, sqllog.Config{})
+	st, err := db.Prepare("CONVERT ?")
+	require.NoError(err)
+	defer st.Close()
+	var v string
+	require.NoError(st.QueryRow(1).Scan(&v))
+	require.Equal("converted 1", v)
+}
@@ -0,0 +1,62 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredStack(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("stack", golog.Config{
+		Encoding: golog.JSONEncoding,
+		JSONEncoder: golog.JSONEncoderConfig{
+			DisableTimestamp: true,
+			StructuredStack:  true,
+			StackDepth:       2,
+		},
+		StacktraceLevels: []golog.Level{golog.ERROR},
+		Handler:          golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(err)
+	log.Error("failed")
+
+	var out struct {
+		Stack []struct {
+			Function string `json:"function"`
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+		} `json:"stack"`
+	}
+	require.NoError(json.Unmarshal(buf.Bytes(), &out), buf.String())
+	require.Len(out.Stack, 2)
+	require.Equal("github.com/millken/golog_test.TestStructuredStack", out.Stack[0].Function)
+	require.True(strings.HasSuffix(out.Stack[0].File, "stack_test.go"))
+	require.NotZero(out.Stack[0].Line)
+	require.Equal("testing.tRunner", out.Stack[1].Function)
+}
+
+func TestFilterStack(t *testing.T) {
+	var buf buffer.Buffer
+	log, err := golog.NewLoggerByConfig("stack", golog.Config{
+		TextEncoder: golog.TextEncoderConfig{
+			DisableTimestamp: true,
+			DisableColor:     true,
+			FilterStack:      true,
+			// Skip back into golog, whose frames are then filtered out.
+			CallerSkipFrame: -2,
+		},
+		StacktraceLevels: []golog.Level{golog.ERROR},
+		Handler:          golog.HandlerConfig{Type: golog.HandlerTypeCustom, Writer: &buf},
+	})
+	require.NoError(t, err)
+	log.Error("failed")
+	require.Contains(t, buf.String(), "golog_test.TestFilterStack")
+	require.NotContains(t, buf.String(), "golog.(*Log)")
+	require.NotContains(t, buf.String(), "runtime.")
+}
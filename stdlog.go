@@ -0,0 +1,166 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// maxLineLength is the length from which LineWriter logs a line without waiting for its end.
+const maxLineLength = 64 << 10
+
+// LineWriterConfig is the configuration for LineWriter.
+type LineWriterConfig struct {
+	// Level is the level of the entries, INFO if not set. FATAL and PANIC are logged as ERROR.
+	Level Level `json:"level" yaml:"level"`
+	// DetectLevel takes the level of a line from a prefix such as "[ERROR]", "ERROR:" or
+	// "[warn]", which is then removed from the message.
+	DetectLevel bool `json:"detectLevel" yaml:"detectLevel"`
+}
+
+// LineWriter is an io.Writer logging each line written as an entry, such as the output
+// of a command or of a log.Logger. It never exits nor panics.
+type LineWriter struct {
+	logger Logger
+	cfg    LineWriterConfig
+	fn     func(line string)
+	mu     sync.Mutex
+	buf    []byte
+}
+
+var _ io.WriteCloser = (*LineWriter)(nil)
+
+// NewLineWriter returns a LineWriter logging with logger.
+func NewLineWriter(logger Logger, cfg LineWriterConfig) *LineWriter {
+	if cfg.Level == 0 {
+		cfg.Level = INFO
+	}
+	return &LineWriter{logger: logger, cfg: cfg}
+}
+
+// NewLineFuncWriter returns a LineWriter calling fn with each line instead of logging it,
+// for writers handling lines on their own. The calls are serialized.
+func NewLineFuncWriter(fn func(line string)) *LineWriter {
+	return &LineWriter{fn: fn}
+}
+
+// Write logs the complete lines of p, keeping the last one until its end is written.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	consumed := 0
+	for {
+		i := bytes.IndexByte(w.buf[consumed:], '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(w.buf[consumed : consumed+i])
+		consumed += i + 1
+	}
+	if len(w.buf)-consumed >= maxLineLength {
+		w.logLine(w.buf[consumed:])
+		consumed = len(w.buf)
+	}
+	// Move the incomplete line to the start, so that the buffer is reused and does not grow.
+	n := copy(w.buf, w.buf[consumed:])
+	w.buf = w.buf[:n]
+	return len(p), nil
+}
+
+// Close logs the last line if it is incomplete.
+func (w *LineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.logLine(w.buf)
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+func (w *LineWriter) logLine(line []byte) {
+	msg := string(bytes.TrimSuffix(line, []byte("\r")))
+	if w.fn != nil {
+		w.fn(msg)
+		return
+	}
+	level := w.cfg.Level
+	if w.cfg.DetectLevel {
+		if l, rest, ok := ParseLevelPrefix(msg); ok {
+			level, msg = l, rest
+		}
+	}
+	if level < ERROR {
+		level = ERROR
+	}
+	LogFieldsTo(w.logger, level, msg)
+}
+
+// levelPrefixes maps the lowercased level names found in line prefixes to levels.
+var levelPrefixes = map[string]Level{
+	"panic":    PANIC,
+	"fatal":    FATAL,
+	"critical": ERROR,
+	"crit":     ERROR,
+	"error":    ERROR,
+	"err":      ERROR,
+	"warning":  WARNING,
+	"warn":     WARNING,
+	"info":     INFO,
+	"notice":   INFO,
+	"debug":    DEBUG,
+	"trace":    DEBUG,
+}
+
+// ParseLevelPrefix parses a level prefix of msg, as in "[ERROR] msg", "ERROR: msg" or
+// "[warn] msg", and returns the level and the rest of msg.
+func ParseLevelPrefix(msg string) (Level, string, bool) {
+	s := strings.TrimLeft(msg, " \t")
+	var name, rest string
+	if strings.HasPrefix(s, "[") {
+		i := strings.IndexByte(s, ']')
+		if i < 0 {
+			return 0, msg, false
+		}
+		name, rest = s[1:i], s[i+1:]
+	} else {
+		i := strings.IndexAny(s, ": \t")
+		if i < 0 {
+			return 0, msg, false
+		}
+		name, rest = s[:i], strings.TrimPrefix(s[i:], ":")
+		// Only names in capitals or followed by a colon are taken for levels.
+		if s[i] != ':' && name != strings.ToUpper(name) {
+			return 0, msg, false
+		}
+	}
+	level, ok := levelPrefixes[strings.ToLower(name)]
+	if !ok {
+		return 0, msg, false
+	}
+	return level, strings.TrimLeft(rest, " \t"), true
+}
+
+// NewStdLogger returns a log.Logger logging each message with logger at level.
+func NewStdLogger(logger Logger, level Level) *log.Logger {
+	return log.New(NewLineWriter(logger, LineWriterConfig{Level: level}), "", 0)
+}
+
+// RedirectStdLog redirects the output of the standard logger of the log package to logger
+// at level, detecting the level of messages with a prefix such as "[ERROR]". The returned
+// function restores the output, the prefix and the flags of the standard logger.
+func RedirectStdLog(logger Logger, level Level) (restore func()) {
+	std := log.Default()
+	out, prefix, flags := std.Writer(), std.Prefix(), std.Flags()
+	std.SetOutput(NewLineWriter(logger, LineWriterConfig{Level: level, DetectLevel: true}))
+	std.SetPrefix("")
+	std.SetFlags(0)
+	return func() {
+		std.SetOutput(out)
+		std.SetPrefix(prefix)
+		std.SetFlags(flags)
+	}
+}
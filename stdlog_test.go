@@ -0,0 +1,91 @@
+package golog_test
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/gologtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	require := require.New(t)
+	logger, obs := gologtest.NewLogger(t, "lines")
+	w := golog.NewLineWriter(logger, golog.LineWriterConfig{DetectLevel: true})
+
+	_, err := w.Write([]byte("first\r\nsec"))
+	require.NoError(err)
+	_, err = w.Write([]byte("ond\n[ERROR] failed\nWARN: slow\n  [debug]  details\nerror in lowercase\nFATAL: exit\nlast"))
+	require.NoError(err)
+	require.Equal([]string{"first", "second", "failed", "slow", "details", "error in lowercase", "exit"}, obs.All().Messages())
+	require.NoError(w.Close())
+
+	entries := obs.All()
+	require.Equal([]string{"first", "second", "failed", "slow", "details", "error in lowercase", "exit", "last"}, entries.Messages())
+	levels := make([]golog.Level, len(entries))
+	for i, e := range entries {
+		levels[i] = e.Level
+	}
+	require.Equal([]golog.Level{
+		golog.INFO, golog.INFO, golog.ERROR, golog.WARNING, golog.DEBUG, golog.INFO, golog.ERROR, golog.INFO,
+	}, levels)
+}
+
+func TestLineWriter_LongLine(t *testing.T) {
+	logger, obs := gologtest.NewLogger(t, "lines")
+	w := golog.NewLineWriter(logger, golog.LineWriterConfig{Level: golog.WARNING})
+	_, err := w.Write(bytes.Repeat([]byte("a"), 100<<10))
+	require.NoError(t, err)
+	require.Equal(t, 1, obs.Len())
+	require.Equal(t, golog.WARNING, obs.All()[0].Level)
+}
+
+func TestLineFuncWriter(t *testing.T) {
+	var lines []string
+	w := golog.NewLineFuncWriter(func(line string) { lines = append(lines, line) })
+	_, err := w.Write([]byte("a\r\nb"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("c\n\nd"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, []string{"a", "bc", "", "d"}, lines)
+}
+
+func TestNewStdLogger(t *testing.T) {
+	logger, obs := gologtest.NewLogger(t, "http")
+	std := golog.NewStdLogger(logger, golog.ERROR)
+	std.Printf("http: TLS handshake error from %s: EOF", "127.0.0.1:1234")
+	gologtest.AssertLogged(t, obs, golog.ERROR, "http: TLS handshake error from 127.0.0.1:1234: EOF")
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	require := require.New(t)
+	w, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	defer func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	}()
+	var out strings.Builder
+	log.SetOutput(&out)
+	log.SetFlags(log.LstdFlags)
+	log.SetPrefix("app: ")
+
+	logger, obs := gologtest.NewLogger(t, "std")
+	restore := golog.RedirectStdLog(logger, golog.INFO)
+	log.Print("started")
+	log.Println("[WARNING] disk almost full")
+	restore()
+
+	entries := obs.All()
+	require.Equal([]string{"started", "disk almost full"}, entries.Messages())
+	require.Equal(golog.INFO, entries[0].Level)
+	require.Equal(golog.WARNING, entries[1].Level)
+	require.Empty(out.String())
+	require.Equal(log.LstdFlags, log.Flags())
+	require.Equal("app: ", log.Prefix())
+	require.Same(&out, log.Writer())
+}
@@ -0,0 +1,270 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultStreamBufferSize is the number of entries queued per client if StreamerConfig.BufferSize is not set.
+const defaultStreamBufferSize = 256
+
+// StreamerConfig is the configuration for Streamer.
+type StreamerConfig struct {
+	// Level is the least severe level streamed, INFO if not set, even if filtered out of
+	// the writer of the logger. Entries of the levels streamed are encoded even without clients.
+	Level Level `json:"level" yaml:"level"`
+	// BufferSize is the number of entries queued for a client, 256 if not set. Clients
+	// falling further behind are disconnected.
+	BufferSize int `json:"bufferSize" yaml:"bufferSize"`
+}
+
+// Streamer is an EntryHandler streaming the entries of the loggers it is set on to HTTP clients,
+// as Server-Sent Events or newline delimited JSON. Logging never waits for clients.
+//
+// Clients choose the format with the query parameter format, sse or ndjson, or else with
+// the Accept header, and filter the entries with the query parameters:
+//
+//	level   the least severe level of the entries, e.g. level=warning
+//	module  a glob matching the module of the entries, e.g. module=db/*
+//	field   key:value, matching the entries with a field of key, dotted for groups,
+//	        whose value prints as value. It may be repeated.
+type Streamer struct {
+	cfg     StreamerConfig
+	encoder *JSONEncoder
+	mu      sync.RWMutex
+	clients map[*streamClient]struct{}
+	count   atomic.Int32
+}
+
+var _ DataHandler = (*Streamer)(nil)
+
+// NewStreamer creates a new Streamer, to be set in Config.EntryHandlers and served over HTTP.
+func NewStreamer(cfg StreamerConfig) *Streamer {
+	if cfg.Level == 0 {
+		cfg.Level = INFO
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultStreamBufferSize
+	}
+	return &Streamer{
+		cfg:     cfg,
+		encoder: NewJSONEncoder(JSONEncoderConfig{ShowModuleName: true}),
+		clients: make(map[*streamClient]struct{}),
+	}
+}
+
+// streamClient is a connected client.
+type streamClient struct {
+	filter  streamFilter
+	ch      chan []byte
+	dropped chan struct{}
+	once    sync.Once
+}
+
+func (c *streamClient) drop() {
+	c.once.Do(func() { close(c.dropped) })
+}
+
+// streamFilter holds the filters of a client.
+type streamFilter struct {
+	level  Level
+	module string
+	fields [][2]string
+}
+
+func parseStreamFilter(r *http.Request) (streamFilter, error) {
+	q := r.URL.Query()
+	f := streamFilter{level: DEBUG, module: q.Get("module")}
+	if s := q.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return f, err
+		}
+		f.level = level
+	}
+	if f.module != "" {
+		if _, err := path.Match(f.module, ""); err != nil {
+			return f, fmt.Errorf("invalid module pattern: %s", f.module)
+		}
+	}
+	for _, s := range q["field"] {
+		key, value, ok := strings.Cut(s, ":")
+		if !ok {
+			return f, fmt.Errorf("invalid field filter, want key:value: %s", s)
+		}
+		f.fields = append(f.fields, [2]string{key, value})
+	}
+	return f, nil
+}
+
+func (f streamFilter) match(e *Entry, fields func() []Field) bool {
+	if e.Level > f.level {
+		return false
+	}
+	if f.module != "" {
+		if ok, _ := path.Match(f.module, e.Module); !ok {
+			return false
+		}
+	}
+	for _, kv := range f.fields {
+		field, ok := lookupField(fields(), kv[0])
+		if !ok || fmt.Sprint(field.Value()) != kv[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupField returns the last field of fields with key, dotted for groups.
+func lookupField(fields []Field, key string) (Field, bool) {
+	name, rest, nested := strings.Cut(key, ".")
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key != name {
+			continue
+		}
+		if !nested {
+			return fields[i], true
+		}
+		if children, ok := fields[i].Val.([]Field); ok && fields[i].Type == GroupType {
+			return lookupField(children, rest)
+		}
+		return Field{}, false
+	}
+	return Field{}, false
+}
+
+// Enabled implements EntryHandler.
+func (s *Streamer) Enabled(level Level) bool {
+	return level <= s.cfg.Level
+}
+
+// NeedsData implements DataHandler: the streamer encodes entries with its own encoder.
+func (s *Streamer) NeedsData() bool {
+	return false
+}
+
+// Clients returns the number of connected clients.
+func (s *Streamer) Clients() int {
+	return int(s.count.Load())
+}
+
+// Handle implements EntryHandler, queuing e for the clients it matches.
+func (s *Streamer) Handle(e *Entry) error {
+	if s.count.Load() == 0 {
+		return nil
+	}
+	var nested []Field
+	fields := func() []Field {
+		if nested == nil {
+			nested = e.NestedFields()
+		}
+		return nested
+	}
+	var data []byte
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for c := range s.clients {
+		if !c.filter.match(e, fields) {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = s.encode(e); err != nil {
+				return err
+			}
+		}
+		select {
+		case c.ch <- data:
+		default:
+			c.drop()
+		}
+	}
+	return nil
+}
+
+// encode encodes e as JSON, whatever the encoding of the logger.
+func (s *Streamer) encode(e *Entry) ([]byte, error) {
+	tmp := acquireEntry()
+	defer releaseEntry(tmp)
+	tmp.Time = e.Time
+	tmp.Level = e.Level
+	tmp.Module = e.Module
+	tmp.Message = e.Message
+	tmp.Fields = append(tmp.Fields, e.Fields[:e.fieldsLen]...)
+	tmp.SetFieldsLen(e.fieldsLen)
+	b, err := s.encoder.Encode(tmp)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(bytes.TrimSuffix(b, []byte("\n"))), nil
+}
+
+// ServeHTTP streams the entries to the client until it disconnects or falls behind.
+func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStreamFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sse := false
+	switch r.URL.Query().Get("format") {
+	case "sse":
+		sse = true
+	case "ndjson":
+	case "":
+		sse = strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	default:
+		http.Error(w, "unknown format: "+r.URL.Query().Get("format"), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c := &streamClient{filter: filter, ch: make(chan []byte, s.cfg.BufferSize), dropped: make(chan struct{})}
+	s.mu.Lock()
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+	s.count.Add(1)
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+		s.count.Add(-1)
+	}()
+
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case data := <-c.ch:
+			if sse {
+				_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+			} else {
+				_, err = fmt.Fprintf(w, "%s\n", data)
+			}
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-c.dropped:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package golog_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/millken/golog"
+	"github.com/stretchr/testify/require"
+)
+
+// streamConfig returns the config of loggers whose entries are handled by s.
+func streamConfig(s *golog.Streamer) golog.Config {
+	return golog.Config{
+		Level:         golog.WARNING,
+		Encoding:      golog.TextEncoding,
+		EntryHandlers: []golog.EntryHandler{s},
+		Clock:         golog.ClockFunc(func() time.Time { return time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC) }),
+	}
+}
+
+// connect connects to the streamer and waits for it to register the client.
+func connect(t *testing.T, srv *httptest.Server, s *golog.Streamer, query string, header http.Header) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	clients := s.Clients()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"?"+query, nil)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, func() bool { return s.Clients() > clients }, time.Second, time.Millisecond)
+	return bufio.NewReader(resp.Body)
+}
+
+func readLine(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	return line
+}
+
+func TestStreamer(t *testing.T) {
+	require := require.New(t)
+	s := golog.NewStreamer(golog.StreamerConfig{Level: golog.DEBUG})
+	srv := httptest.NewServer(s)
+	// Closed after the clients disconnect, as cleanups run last registered first.
+	t.Cleanup(srv.Close)
+	db := newTestLogger(t, "db/sql", io.Discard, streamConfig(s))
+	web := newTestLogger(t, "web", io.Discard, streamConfig(s))
+
+	ndjson := connect(t, srv, s, "format=ndjson&module=db/*&field=req.id:7", nil)
+	sse := connect(t, srv, s, "level=warning", http.Header{"Accept": {"text/event-stream"}})
+
+	db.WithGroup("req").Debug("query", "id", 7)
+	db.Debug("query", "id", 7)
+	web.Info("request")
+	web.Error("failed")
+
+	require.Equal(`{"time":"2024-01-02T00:00:00Z","level":"debug","module":"db/sql","message":"query","req":{"id":7}}`+"\n", readLine(t, ndjson))
+	require.Equal(`data: {"time":"2024-01-02T00:00:00Z","level":"error","module":"web","message":"failed"}`+"\n", readLine(t, sse))
+	require.Equal("\n", readLine(t, sse))
+}
+
+func TestStreamer_BadRequest(t *testing.T) {
+	s := golog.NewStreamer(golog.StreamerConfig{})
+	for _, query := range []string{"level=loud", "module=[", "field=id", "format=xml"} {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+// blockingWriter is a ResponseWriter whose writes block until unblocked.
+type blockingWriter struct {
+	*httptest.ResponseRecorder
+	block chan struct{}
+}
+
+func (w *blockingWriter) Write(b []byte) (int, error) {
+	<-w.block
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestStreamer_DropsSlowClients(t *testing.T) {
+	require := require.New(t)
+	s := golog.NewStreamer(golog.StreamerConfig{BufferSize: 1})
+	log := newTestLogger(t, "slow", io.Discard, streamConfig(s))
+	w := &blockingWriter{ResponseRecorder: httptest.NewRecorder(), block: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	require.Eventually(func() bool { return s.Clients() == 1 }, time.Second, time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		log.Info("entry")
+	}
+	close(w.block)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("slow client not dropped")
+	}
+	require.Zero(s.Clients())
+}
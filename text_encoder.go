@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/millken/golog/internal/buffer"
@@ -13,7 +15,12 @@ import (
 )
 
 var (
-	_ Encoder = (*TextEncoder)(nil)
+	_ Encoder       = (*TextEncoder)(nil)
+	_ ObjectEncoder = (*textObjectEncoder)(nil)
+	_ FieldEncoder  = (*textValueEncoder)(nil)
+
+	textObjectEncoderPool = sync.Pool{New: func() any { return &textObjectEncoder{} }}
+	textValueEncoderPool  = sync.Pool{New: func() any { return &textValueEncoder{} }}
 )
 
 // DefaultLineEnding is the default line ending used by the text encoder.
@@ -39,7 +46,8 @@ const (
 
 // TextEncoder encodes entries to the text.
 type TextEncoder struct {
-	cfg TextEncoderConfig
+	cfg   TextEncoderConfig
+	types *TypeEncoders
 }
 
 // NewTextEncoder returns a new text encoder.
@@ -67,14 +75,16 @@ func (o *TextEncoder) Encode(e *Entry) ([]byte, error) {
 	}
 	if e.HasFlag(FlagCaller) || e.HasFlag(FlagStacktrace) {
 		stackSkip := int(DefaultCallerSkip.Load()) + e.CallerSkip() + o.cfg.CallerSkipFrame
-		frames := stack.Tracer(stackSkip, e.HasFlag(FlagStacktrace))
+		frames := stack.Tracer(stackSkip, e.HasFlag(FlagStacktrace), o.cfg.StackDepth)
 
 		if len(frames) > 0 {
 			if e.HasFlag(FlagCaller) {
-				frame := frames[0]
-				e.SetCaller(frame.File + ":" + strconv.Itoa(frame.Line))
+				e.SetCaller(callerString(frames[0], o.cfg.CallerFormat, o.cfg.CallerFunction, o.cfg.CallerFormatter))
 			}
 			if e.HasFlag(FlagStacktrace) {
+				if o.cfg.FilterStack {
+					frames = stack.Filter(frames, isInternalFrame)
+				}
 				buffer := buffer.Get()
 				stackfmt := stack.NewStackFormatter(buffer)
 				stackfmt.FormatFrames(frames)
@@ -96,7 +106,7 @@ func (o *TextEncoder) Encode(e *Entry) ([]byte, error) {
 			e.WriteByte(' ')
 		}
 	}
-	writeFields(e)
+	writeFields(e, o.cfg.ErrorFormat, o.types)
 	if e.HasFlag(FlagStacktrace) {
 		e.WriteByte(DefaultLineEnding)
 		e.WriteString(stacktraces)
@@ -125,15 +135,30 @@ func writePart(e *Entry, p string, cfg *TextEncoderConfig) {
 	}
 }
 
-func writeFields(e *Entry) {
+func writeFields(e *Entry, errorFormat ErrorFormat, types *TypeEncoders) {
 	if len(e.Fields) == 0 {
 		return
 	}
-	for _, v := range e.Fields[:e.FieldsLength()] {
-		e.WriteByte(' ')
-		defaultFormatFieldName(e, v.Key)
-		defaultFormatFieldValue(e, v.Val)
+	oe := textObjectEncoderPool.Get().(*textObjectEncoder)
+	oe.e = e
+	oe.errorFormat = errorFormat
+	oe.types = types
+	oe.writeFields(e.Fields[:e.FieldsLength()])
+	oe.e, oe.types = nil, nil
+	oe.prefix = oe.prefix[:0]
+	textObjectEncoderPool.Put(oe)
+}
+
+// fieldObject returns the ObjectMarshaler held by f, if any.
+func fieldObject(f Field) (ObjectMarshaler, bool) {
+	switch f.Type {
+	case ObjectMarshalerType:
+		return f.Val.(ObjectMarshaler), true
+	case AnyType:
+		m, ok := f.Val.(ObjectMarshaler)
+		return m, ok
 	}
+	return nil, false
 }
 
 func defaultFormatLevel(e *Entry) {
@@ -163,7 +188,7 @@ func defaultModuleName(e *Entry) {
 }
 
 func defaultFormatTimestamp(e *Entry, timeFormat string) {
-	e.Data = time.Now().AppendFormat(e.Data, timeFormat)
+	e.Data = entryTime(e).AppendFormat(e.Data, timeFormat)
 }
 
 func defaultFormatMessage(e *Entry) {
@@ -178,24 +203,48 @@ func defaultFormatCaller(e *Entry) {
 	ansiColorize(e.GetCaller(), colorBold, true, e)
 }
 
-func defaultFormatFieldName(e *Entry, name string) {
-	const equal string = "="
-	if e.HasFlag(FlagNoColor) {
-		e.Data = append(e.Data, name...)
-		e.Data = append(e.Data, equal...)
-		return
+// defaultFormatField formats the value of f, using its unboxed storage when it has one.
+func defaultFormatField(e *Entry, f Field, types *TypeEncoders) {
+	switch f.Type {
+	case StringType:
+		defaultFormatString(e, f.Str)
+	case Int64Type:
+		e.Data = strconv.AppendInt(e.Data, f.Integer, 10)
+	case Uint64Type:
+		e.Data = strconv.AppendUint(e.Data, uint64(f.Integer), 10)
+	case Float64Type:
+		e.Data = strconv.AppendFloat(e.Data, math.Float64frombits(uint64(f.Integer)), 'f', -1, 64)
+	case BoolType:
+		e.Data = strconv.AppendBool(e.Data, f.Integer == 1)
+	case DurationType:
+		e.Data = append(e.Data, time.Duration(f.Integer).String()...)
+	case TimeType:
+		e.Data = f.timeValue().AppendFormat(e.Data, textDefaultTimeFormat)
+	case StringerType:
+		defaultFormatString(e, f.Val.(fmt.Stringer).String())
+	case ObjectMarshalerType:
+		e.Data = appendObject(e.Data, f.Val.(ObjectMarshaler), types)
+	case ArrayMarshalerType:
+		e.Data = appendArray(e.Data, f.Val.(ArrayMarshaler), types)
+	default:
+		defaultFormatFieldValue(e, f.Val, types)
 	}
-	ansiColorize(name+equal, colorCyan, false, e)
 }
 
-func defaultFormatFieldValue(e *Entry, value any) {
+func defaultFormatString(e *Entry, s string) {
+	if needsQuote(s) {
+		e.Data = strconv.AppendQuote(e.Data, s)
+	} else {
+		e.Data = append(e.Data, s...)
+	}
+}
+
+// defaultFormatFieldValue formats value. Errors and the values of types without built-in rendering
+// are rendered by their type encoder in types or in the global registry when they have one.
+func defaultFormatFieldValue(e *Entry, value any, types *TypeEncoders) {
 	switch fValue := value.(type) {
 	case string:
-		if needsQuote(fValue) {
-			e.Data = append(e.Data, strconv.Quote(fValue)...)
-		} else {
-			e.Data = append(e.Data, fValue...)
-		}
+		defaultFormatString(e, fValue)
 	case int:
 		e.Data = strconv.AppendInt(e.Data, int64(fValue), 10)
 	case int8:
@@ -223,6 +272,10 @@ func defaultFormatFieldValue(e *Entry, value any) {
 	case bool:
 		e.Data = strconv.AppendBool(e.Data, fValue)
 	case error:
+		if fn, ok := lookupTypeEncoder(types, fValue); ok {
+			formatTypeEncoded(e, fn, fValue, types)
+			return
+		}
 		e.Data = append(e.Data, fValue.Error()...)
 	case []byte:
 		e.Data = append(e.Data, fValue...)
@@ -232,7 +285,17 @@ func defaultFormatFieldValue(e *Entry, value any) {
 		e.Data = append(e.Data, fValue.String()...)
 	case json.Number:
 		e.Data = append(e.Data, fValue.String()...)
+	case SecretValue:
+		e.Data = append(e.Data, redactedMask...)
+	case ObjectMarshaler:
+		e.Data = appendObject(e.Data, fValue, types)
+	case ArrayMarshaler:
+		e.Data = appendArray(e.Data, fValue, types)
 	default:
+		if fn, ok := lookupTypeEncoder(types, fValue); ok {
+			formatTypeEncoded(e, fn, fValue, types)
+			return
+		}
 		b, err := json.Marshal(fValue)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
@@ -280,3 +343,225 @@ func textDefaultPartsOrder() []string {
 		MessageFieldName,
 	}
 }
+
+// textObjectEncoder is the ObjectEncoder used for text output.
+// Nested keys are joined with dots; arrays are rendered as JSON.
+type textObjectEncoder struct {
+	e           *Entry
+	prefix      []byte
+	errorFormat ErrorFormat
+	types       *TypeEncoders
+}
+
+// addKey writes the separator and the dotted key of the next value.
+func (o *textObjectEncoder) addKey(key string) {
+	e := o.e
+	e.WriteByte(' ')
+	noColor := e.HasFlag(FlagNoColor)
+	if !noColor {
+		_, _ = e.WriteString(ansiBold)
+		_, _ = e.WriteString(strconv.Itoa(colorCyan))
+		_, _ = e.WriteString("m")
+	}
+	e.Data = append(e.Data, o.prefix...)
+	e.Data = append(e.Data, key...)
+	e.WriteByte('=')
+	if !noColor {
+		_, _ = e.WriteString(ansiReset)
+	}
+}
+
+// writeFields writes fields as dotted keys, prefixing the fields of groups and objects with their name.
+func (o *textObjectEncoder) writeFields(fields []Field) {
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if f.Type == GroupType {
+			children := groupFields(fields, i)
+			i += len(children)
+			if isEmptyGroup(children) {
+				continue
+			}
+			n := len(o.prefix)
+			o.prefix = append(o.prefix, f.Key...)
+			o.prefix = append(o.prefix, '.')
+			o.writeFields(children)
+			o.prefix = o.prefix[:n]
+			continue
+		}
+		if m, ok := fieldObject(f); ok {
+			_ = o.AddObject(f.Key, m)
+			continue
+		}
+		if o.errorFormat == ErrorFormatStructured {
+			if err, ok := fieldError(f); ok {
+				_ = o.AddObject(f.Key, errorObject{err: err})
+				continue
+			}
+		}
+		o.addKey(f.Key)
+		defaultFormatField(o.e, f, o.types)
+	}
+}
+
+func (o *textObjectEncoder) AddString(key, val string) {
+	o.addKey(key)
+	defaultFormatString(o.e, val)
+}
+
+func (o *textObjectEncoder) AddInt(key string, val int) {
+	o.AddInt64(key, int64(val))
+}
+
+func (o *textObjectEncoder) AddInt64(key string, val int64) {
+	o.addKey(key)
+	o.e.Data = strconv.AppendInt(o.e.Data, val, 10)
+}
+
+func (o *textObjectEncoder) AddUint64(key string, val uint64) {
+	o.addKey(key)
+	o.e.Data = strconv.AppendUint(o.e.Data, val, 10)
+}
+
+func (o *textObjectEncoder) AddFloat64(key string, val float64) {
+	o.addKey(key)
+	o.e.Data = strconv.AppendFloat(o.e.Data, val, 'f', -1, 64)
+}
+
+func (o *textObjectEncoder) AddBool(key string, val bool) {
+	o.addKey(key)
+	o.e.Data = strconv.AppendBool(o.e.Data, val)
+}
+
+func (o *textObjectEncoder) AddDuration(key string, val time.Duration) {
+	o.addKey(key)
+	o.e.Data = append(o.e.Data, val.String()...)
+}
+
+func (o *textObjectEncoder) AddTime(key string, val time.Time) {
+	o.addKey(key)
+	o.e.Data = val.AppendFormat(o.e.Data, textDefaultTimeFormat)
+}
+
+func (o *textObjectEncoder) AddAny(key string, val any) {
+	if m, ok := val.(ObjectMarshaler); ok {
+		_ = o.AddObject(key, m)
+		return
+	}
+	o.addKey(key)
+	defaultFormatFieldValue(o.e, val, o.types)
+}
+
+func (o *textObjectEncoder) AddObject(key string, val ObjectMarshaler) error {
+	n := len(o.prefix)
+	o.prefix = append(o.prefix, key...)
+	o.prefix = append(o.prefix, '.')
+	err := val.MarshalLogObject(o)
+	if err != nil {
+		o.AddString(ErrorFieldName, err.Error())
+	}
+	o.prefix = o.prefix[:n]
+	return err
+}
+
+func (o *textObjectEncoder) AddArray(key string, val ArrayMarshaler) error {
+	o.addKey(key)
+	o.e.Data = appendArray(o.e.Data, val, o.types)
+	return nil
+}
+
+// formatTypeEncoded formats value as rendered by the type encoder fn.
+func formatTypeEncoded(e *Entry, fn typeEncoderFunc, value any, types *TypeEncoders) {
+	ve := textValueEncoderPool.Get().(*textValueEncoder)
+	ve.e, ve.types, ve.done = e, types, false
+	fn(ve, value)
+	if !ve.done {
+		e.Data = append(e.Data, "null"...)
+	}
+	ve.e, ve.types = nil, nil
+	textValueEncoderPool.Put(ve)
+}
+
+// textValueEncoder is the FieldEncoder used for text output. It keeps the first value only;
+// objects and arrays are rendered as JSON.
+type textValueEncoder struct {
+	e     *Entry
+	types *TypeEncoders
+	done  bool
+}
+
+// first reports whether no value was written yet, and marks one as written.
+func (v *textValueEncoder) first() bool {
+	if v.done {
+		return false
+	}
+	v.done = true
+	return true
+}
+
+func (v *textValueEncoder) AppendString(val string) {
+	if v.first() {
+		defaultFormatString(v.e, val)
+	}
+}
+
+func (v *textValueEncoder) AppendInt(val int) {
+	v.AppendInt64(int64(val))
+}
+
+func (v *textValueEncoder) AppendInt64(val int64) {
+	if v.first() {
+		v.e.Data = strconv.AppendInt(v.e.Data, val, 10)
+	}
+}
+
+func (v *textValueEncoder) AppendUint64(val uint64) {
+	if v.first() {
+		v.e.Data = strconv.AppendUint(v.e.Data, val, 10)
+	}
+}
+
+func (v *textValueEncoder) AppendFloat64(val float64) {
+	if v.first() {
+		v.e.Data = strconv.AppendFloat(v.e.Data, val, 'f', -1, 64)
+	}
+}
+
+func (v *textValueEncoder) AppendBool(val bool) {
+	if v.first() {
+		v.e.Data = strconv.AppendBool(v.e.Data, val)
+	}
+}
+
+func (v *textValueEncoder) AppendDuration(val time.Duration) {
+	if v.first() {
+		v.e.Data = append(v.e.Data, val.String()...)
+	}
+}
+
+func (v *textValueEncoder) AppendTime(val time.Time) {
+	if v.first() {
+		v.e.Data = val.AppendFormat(v.e.Data, textDefaultTimeFormat)
+	}
+}
+
+func (v *textValueEncoder) AppendAny(val any) {
+	if v.first() {
+		defaultFormatFieldValue(v.e, val, v.types)
+	}
+}
+
+func (v *textValueEncoder) AppendObject(val ObjectMarshaler) error {
+	if !v.first() {
+		return nil
+	}
+	var err error
+	v.e.Data, err = appendObjectErr(v.e.Data, val, v.types)
+	return err
+}
+
+func (v *textValueEncoder) AppendArray(val ArrayMarshaler) error {
+	if v.first() {
+		v.e.Data = appendArray(v.e.Data, val, v.types)
+	}
+	return nil
+}
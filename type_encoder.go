@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldEncoder writes a single value. A type encoder should call exactly one of its methods;
+// the following calls are ignored and a value is rendered as null when none is made.
+type FieldEncoder interface {
+	ArrayEncoder
+}
+
+// TypeEncoders is a registry of encoders for values of specific types, consulted by the
+// encoders for errors and for the values they have no built-in rendering for, so that the
+// encoders of types such as string, int or time.Time are never called. It is safe for concurrent use.
+type TypeEncoders struct {
+	mu  sync.Mutex // serializes registrations
+	set atomic.Pointer[typeEncoderSet]
+}
+
+// typeEncoderSet is an immutable snapshot of a TypeEncoders.
+type typeEncoderSet struct {
+	// concrete holds the encoders of non-interface types.
+	concrete map[reflect.Type]typeEncoderFunc
+	// ifaces holds the encoders of interface types, in registration order.
+	ifaces []ifaceEncoder
+}
+
+type typeEncoderFunc func(FieldEncoder, any)
+
+type ifaceEncoder struct {
+	typ   reflect.Type
+	match func(any) bool
+	fn    typeEncoderFunc
+}
+
+var globalTypeEncoders = NewTypeEncoders()
+
+// NewTypeEncoders returns an empty registry, to be set as Config.TypeEncoders.
+func NewTypeEncoders() *TypeEncoders {
+	return &TypeEncoders{}
+}
+
+// RegisterTypeEncoder registers fn as the global encoder of the values of type T.
+// T may be an interface, such as error, in which case fn applies to the values implementing it
+// that have no encoder for their concrete type. Registering T again replaces its encoder.
+// The encoders of a logger's Config.TypeEncoders take precedence over the global ones.
+func RegisterTypeEncoder[T any](fn func(enc FieldEncoder, v T)) {
+	AddTypeEncoder(globalTypeEncoders, fn)
+}
+
+// AddTypeEncoder registers fn in r as the encoder of the values of type T.
+// See RegisterTypeEncoder.
+func AddTypeEncoder[T any](r *TypeEncoders, fn func(enc FieldEncoder, v T)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	wrapped := func(fe FieldEncoder, v any) { fn(fe, v.(T)) }
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := &typeEncoderSet{concrete: map[reflect.Type]typeEncoderFunc{}}
+	if prev := r.set.Load(); prev != nil {
+		for k, v := range prev.concrete {
+			next.concrete[k] = v
+		}
+		for _, ie := range prev.ifaces {
+			if ie.typ != typ {
+				next.ifaces = append(next.ifaces, ie)
+			}
+		}
+	}
+	if typ.Kind() == reflect.Interface {
+		next.ifaces = append(next.ifaces, ifaceEncoder{
+			typ:   typ,
+			match: func(v any) bool { _, ok := v.(T); return ok },
+			fn:    wrapped,
+		})
+	} else {
+		next.concrete[typ] = wrapped
+	}
+	r.set.Store(next)
+}
+
+// lookup returns the encoder registered in r for v.
+func (r *TypeEncoders) lookup(v any) (typeEncoderFunc, bool) {
+	if r == nil || v == nil {
+		return nil, false
+	}
+	set := r.set.Load()
+	if set == nil {
+		return nil, false
+	}
+	if fn, ok := set.concrete[reflect.TypeOf(v)]; ok {
+		return fn, true
+	}
+	for _, ie := range set.ifaces {
+		if ie.match(v) {
+			return ie.fn, true
+		}
+	}
+	return nil, false
+}
+
+// lookupTypeEncoder returns the encoder of v, looking in types before the global registry.
+func lookupTypeEncoder(types *TypeEncoders, v any) (typeEncoderFunc, bool) {
+	if fn, ok := types.lookup(v); ok {
+		return fn, true
+	}
+	return globalTypeEncoders.lookup(v)
+}
@@ -0,0 +1,99 @@
+package golog_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+type testUUID [4]byte
+
+type testMoney struct {
+	cents    int64
+	currency string
+}
+
+func init() {
+	golog.RegisterTypeEncoder(func(enc golog.FieldEncoder, v testUUID) {
+		enc.AppendString(hex.EncodeToString(v[:]))
+	})
+}
+
+func TestTypeEncoders(t *testing.T) {
+	types := golog.NewTypeEncoders()
+	golog.AddTypeEncoder(types, func(enc golog.FieldEncoder, v testMoney) {
+		_ = enc.AppendObject(golog.ObjectMarshalerFunc(func(oe golog.ObjectEncoder) error {
+			oe.AddInt64("cents", v.cents)
+			oe.AddString("currency", v.currency)
+			return nil
+		}))
+	})
+	golog.AddTypeEncoder(types, func(enc golog.FieldEncoder, err error) {
+		enc.AppendString("err: " + err.Error())
+	})
+	// Built-in types keep their rendering.
+	golog.AddTypeEncoder(types, func(enc golog.FieldEncoder, v bool) {})
+	id := testUUID{0xde, 0xad, 0xbe, 0xef}
+	price := testMoney{cents: 1250, currency: "EUR"}
+
+	tests := []struct {
+		encoding golog.Encoding
+		want     string
+	}{
+		{golog.JSONEncoding, `{"level":"info","message":"typed","id":"deadbeef","price":{"cents":1250,"currency":"EUR"},` +
+			`"error":"err: boom","ok":true,"ids":["deadbeef"]}` + "\n"},
+		{golog.TextEncoding, `INFO typed id=deadbeef price={"cents":1250,"currency":"EUR"} error="err: boom" ok=true ids=["deadbeef"]` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.encoding), func(t *testing.T) {
+			var buf buffer.Buffer
+			log := newTestLogger(t, "types", &buf, golog.Config{Encoding: tt.encoding, TypeEncoders: types})
+			log.Info("typed", "id", id, "price", price, golog.Err(errors.New("boom")), "ok", true,
+				golog.Array("ids", golog.ArrayMarshalerFunc(func(ae golog.ArrayEncoder) error {
+					ae.AppendAny(id)
+					return nil
+				})))
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTypeEncoders_Global(t *testing.T) {
+	require := require.New(t)
+	var buf buffer.Buffer
+	log := newTestLogger(t, "types", &buf, golog.Config{})
+	log.Info("global", "id", testUUID{1, 2, 3, 4}, "price", testMoney{cents: 1})
+	require.Equal(`{"level":"info","message":"global","id":"01020304","price":{}}`+"\n", buf.String())
+
+	types := golog.NewTypeEncoders()
+	golog.AddTypeEncoder(types, func(enc golog.FieldEncoder, v testUUID) {
+		enc.AppendInt(int(v[0]))
+		enc.AppendString("ignored")
+	})
+	buf.Reset()
+	log = newTestLogger(t, "types", &buf, golog.Config{TypeEncoders: types})
+	log.Info("override", "id", testUUID{1, 2, 3, 4})
+	require.Equal(`{"level":"info","message":"override","id":1}`+"\n", buf.String())
+}
+
+func TestTypeEncoders_NoAlloc(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not counted under the race detector")
+	}
+	types := golog.NewTypeEncoders()
+	golog.AddTypeEncoder(types, func(enc golog.FieldEncoder, v testMoney) {
+		enc.AppendInt64(v.cents)
+	})
+	var buf buffer.Buffer
+	log := newTestLogger(t, "types", &buf, golog.Config{TypeEncoders: types})
+	price := golog.Any("price", testMoney{cents: 1250, currency: "EUR"})
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		log.LogFields(golog.INFO, "typed", price)
+	})
+	require.Zero(t, allocs)
+}
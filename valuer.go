@@ -0,0 +1,65 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+)
+
+// maxLogValuerDepth bounds how many times a LogValuer result is itself resolved.
+const maxLogValuerDepth = 16
+
+var errLogValuerDepth = errors.New("golog: LogValue resolved too many times")
+
+// LogValuer is implemented by values that compute their logged value on demand.
+// LogValue is only called when an entry holding the value passes the level check,
+// and at most once per entry. A result that is itself a LogValuer is resolved in turn;
+// a Field result replaces the value and type of the field while keeping its key.
+type LogValuer interface {
+	LogValue() any
+}
+
+// LazyValue is a func adapter that implements LogValuer.
+type LazyValue func() any
+
+// LogValue calls f().
+func (f LazyValue) LogValue() any {
+	return f()
+}
+
+// Lazy returns a value that is computed by fn only when the entry is actually emitted.
+//
+//	log.Debug("request", "body", golog.Lazy(func() any { return dump(req) }))
+func Lazy(fn func() any) LogValuer {
+	return LazyValue(fn)
+}
+
+// resolveField resolves v until it is no longer a LogValuer.
+func resolveField(key string, v LogValuer) Field {
+	for depth := 0; depth < maxLogValuerDepth; depth++ {
+		val := callLogValue(v)
+		switch r := val.(type) {
+		case LogValuer:
+			v = r
+			continue
+		case Field:
+			r.Key = key
+			if next, ok := r.Val.(LogValuer); ok && r.Type == AnyType {
+				v = next
+				continue
+			}
+			return r
+		}
+		return field(key, val)
+	}
+	return NamedErr(key, errLogValuerDepth)
+}
+
+// callLogValue calls v.LogValue, turning a panic into an error value.
+func callLogValue(v LogValuer) (val any) {
+	defer func() {
+		if r := recover(); r != nil {
+			val = fmt.Errorf("golog: LogValue panicked: %v", r)
+		}
+	}()
+	return v.LogValue()
+}
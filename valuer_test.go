@@ -0,0 +1,74 @@
+package golog_test
+
+import (
+	"testing"
+
+	"github.com/millken/golog"
+	"github.com/millken/golog/internal/buffer"
+	"github.com/stretchr/testify/require"
+)
+
+type testValuer struct {
+	calls *int
+	next  any
+}
+
+func (v testValuer) LogValue() any {
+	*v.calls++
+	return v.next
+}
+
+func TestLazy(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetEncoding(golog.JSONEncoding)
+	golog.SetJSONEncoderConfig(golog.JSONEncoderConfig{DisableTimestamp: true})
+
+	calls := 0
+	lazy := golog.Lazy(func() any {
+		calls++
+		return map[string]int{"a": 1}
+	})
+	golog.Debug("skipped", "diff", lazy)
+	require.Zero(calls)
+	require.Empty(buf.String())
+
+	golog.Info("emitted", "diff", lazy)
+	require.Equal(1, calls)
+	require.Equal(`{"level":"info","message":"emitted","diff":{"a":1}}`+"\n", buf.String())
+
+	buf.Reset()
+	l := golog.WithValues("diff", lazy)
+	require.Equal(1, calls)
+	l.Info("with values")
+	require.Equal(2, calls)
+	require.Contains(buf.String(), `"diff":{"a":1}`)
+}
+
+func TestLazy_Recursive(t *testing.T) {
+	defer resetConfigs()
+	require := require.New(t)
+	var buf buffer.Buffer
+	golog.SetWriter(&buf)
+	golog.SetTextEncoderConfig(golog.TextEncoderConfig{DisableTimestamp: true, DisableColor: true})
+
+	calls := 0
+	inner := testValuer{calls: &calls, next: golog.Int("ignored", 7)}
+	outer := testValuer{calls: &calls, next: inner}
+	golog.Info("nested", "v", outer)
+	require.Equal(2, calls)
+	require.Equal("INFO nested v=7\n", buf.String())
+
+	buf.Reset()
+	var loop testValuer
+	loop = testValuer{calls: &calls}
+	loop.next = golog.LazyValue(func() any { return loop })
+	golog.Info("loop", "v", loop)
+	require.Contains(buf.String(), "v=golog: LogValue resolved too many times")
+
+	buf.Reset()
+	golog.Info("panic", "v", golog.Lazy(func() any { panic("boom") }))
+	require.Contains(buf.String(), "v=golog: LogValue panicked: boom")
+}